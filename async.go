@@ -0,0 +1,230 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+const asyncDefaultQueueSize int = 1024
+
+// OverflowPolicy tells what an asynchronous Logger does with a record when its
+// queue is full.
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock waits for room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the record being logged.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest queued record to make room.
+	OverflowDropOldest
+	// OverflowDropBelowLevel drops the record being logged when it is less
+	// severe than AsyncConfig.DropLevel and waits for room otherwise.
+	OverflowDropBelowLevel
+)
+
+// AsyncConfig describes the queue of an asynchronous Logger.
+type AsyncConfig struct {
+	// QueueSize is the number of records the queue holds, 1024 if not positive.
+	QueueSize int
+	Policy    OverflowPolicy
+	// DropLevel is used by OverflowDropBelowLevel, the records with a level
+	// above it, such as LogLevelDebug for LogLevelInfo, are dropped.
+	DropLevel LogLevel
+}
+
+// WithAsync makes the Logger asynchronous: the records are queued in a bounded
+// ring buffer and written by a background goroutine. Flush and Close must be
+// used to make sure the queued records are written.
+func WithAsync(config AsyncConfig) Option {
+	return func(l *Logger) {
+		l.out.async = newAsyncQueue(config, l.out.writeSync)
+	}
+}
+
+type asyncQueue struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	idle     chan struct{}
+	done     chan struct{}
+	buffer   []Record
+	head     int
+	count    int
+	busy     bool
+	closed   bool
+	policy   OverflowPolicy
+	level    LogLevel
+	dropped  atomic.Uint64
+	write    func(r *Record)
+}
+
+func newAsyncQueue(config AsyncConfig, write func(r *Record)) *asyncQueue {
+	if config.QueueSize <= 0 {
+		config.QueueSize = asyncDefaultQueueSize
+	}
+	var q *asyncQueue = &asyncQueue{
+		idle:   make(chan struct{}),
+		done:   make(chan struct{}),
+		buffer: make([]Record, config.QueueSize),
+		policy: config.Policy,
+		level:  config.DropLevel,
+		write:  write,
+	}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+// enqueue returns false when the queue is closed, the record must then be
+// written synchronously by the caller. The records of the FatalXxx methods are
+// never dropped nor blocked, the queue grows to hold them so that they are
+// written in order before the exit, within the fatal timeout.
+func (q *asyncQueue) enqueue(r *Record) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.count == len(q.buffer) && !q.closed {
+		switch {
+		case r.fatal:
+			q.grow()
+		case q.policy == OverflowDropNewest, q.policy == OverflowDropBelowLevel && r.Level > q.level:
+			q.dropped.Add(1)
+			return true
+		case q.policy == OverflowDropOldest:
+			q.buffer[q.head] = Record{}
+			q.head = (q.head + 1) % len(q.buffer)
+			q.count--
+			q.dropped.Add(1)
+		default:
+			q.notFull.Wait()
+		}
+	}
+	if q.closed {
+		return false
+	}
+	q.buffer[(q.head+q.count)%len(q.buffer)] = *r
+	q.count++
+	q.notEmpty.Signal()
+	return true
+}
+
+// grow makes room for one more record, keeping the queued ones in order.
+func (q *asyncQueue) grow() {
+	var buffer []Record = make([]Record, len(q.buffer)+1)
+	for i := 0; i < q.count; i++ {
+		buffer[i] = q.buffer[(q.head+i)%len(q.buffer)]
+	}
+	q.buffer = buffer
+	q.head = 0
+}
+
+func (q *asyncQueue) run() {
+	q.mu.Lock()
+	for {
+		for q.count == 0 && !q.closed {
+			q.notEmpty.Wait()
+		}
+		if q.count == 0 {
+			q.mu.Unlock()
+			close(q.done)
+			return
+		}
+
+		var r Record = q.buffer[q.head]
+		q.buffer[q.head] = Record{}
+		q.head = (q.head + 1) % len(q.buffer)
+		q.count--
+		q.busy = true
+		q.notFull.Signal()
+		q.mu.Unlock()
+
+		q.write(&r)
+
+		q.mu.Lock()
+		q.busy = false
+		if q.count == 0 {
+			close(q.idle)
+			q.idle = make(chan struct{})
+		}
+	}
+}
+
+// flush waits until the queue is drained or ctx is done.
+func (q *asyncQueue) flush(ctx context.Context) error {
+	q.mu.Lock()
+	if q.count == 0 && !q.busy {
+		q.mu.Unlock()
+		return nil
+	}
+	var idle chan struct{} = q.idle
+	q.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close stops accepting records and waits until the queued ones are written
+// or ctx is done.
+func (q *asyncQueue) close(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush waits until the queued records are written, then flushes the sinks
+// having a Flush or Sync method, and returns ctx.Err() if ctx is done first.
+// The records logged meanwhile are written concurrently with the flush, the
+// destinations having a Flush method must be safe for concurrent use.
+func (l *Logger) Flush(ctx context.Context) error {
+	return l.out.flush(ctx)
+}
+
+// Close writes the queued records and stops the background goroutine of an
+// asynchronous Logger, the records logged afterwards are written
+// synchronously. The destinations given by the caller are not closed, only the
+// ones the Logger created: the files opened by NewLoggerFromConfig and
+// ApplyConfig, and the writers of NewOTLPLogger, NewSyslogLogger and
+// NewJournaldLogger.
+func (l *Logger) Close(ctx context.Context) error {
+	var err error
+	if l.out.sampler != nil {
+		l.out.sampler.stop()
+	}
+	if l.out.dedup != nil {
+		l.out.dedup.flush()
+	}
+	if l.out.async != nil {
+		err = l.out.async.close(ctx)
+	}
+	if closeErr := l.out.closeWriters(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Dropped returns the number of records dropped because the queue of an
+// asynchronous Logger was full.
+func (l *Logger) Dropped() uint64 {
+	if l.out.async == nil {
+		return 0
+	}
+	return l.out.async.dropped.Load()
+}
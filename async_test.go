@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedWriter blocks every write until the gate is opened.
+type gatedWriter struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+	gate   chan struct{}
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buffer.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buffer.String()
+}
+
+func TestAsyncFlush(t *testing.T) {
+	var receiver *gatedWriter = newGatedWriter()
+	var logger *Logger = NewLogger(LogLevelInfo, receiver, WithAsync(AsyncConfig{QueueSize: 16}))
+
+	for i := 0; i < 10; i++ {
+		logger.LogInfo("struct", "function", "message %d", -1, i)
+	}
+	if receiver.String() != "" {
+		t.Error("the records must not be written before the gate is opened")
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	if err := logger.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("the flush must time out while the writer is blocked, got %v", err)
+	}
+	cancel()
+
+	close(receiver.gate)
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := logger.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(receiver.String(), "\n") != 10 || !strings.HasSuffix(receiver.String(), "message 9\n") {
+		t.Errorf("the 10 records expected in order, got %q", receiver.String())
+	}
+
+	if err := logger.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	logger.LogInfo("struct", "function", "after close", -1)
+	if !strings.HasSuffix(receiver.String(), "after close\n") {
+		t.Error("the records logged after Close must be written synchronously")
+	}
+}
+
+func testAsyncOverflow(t *testing.T, config AsyncConfig, expectedDropped uint64, expected []string) {
+	var receiver *gatedWriter = newGatedWriter()
+	var logger *Logger = NewLogger(LogLevelDebug, receiver, WithAsync(config))
+
+	// The first record is taken by the background goroutine which blocks on
+	// the gate, the following ones fill the queue.
+	logger.LogInfo("struct", "function", "first", -1)
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		logger.out.async.mu.Lock()
+		var busy bool = logger.out.async.busy
+		logger.out.async.mu.Unlock()
+		if busy {
+			break
+		}
+	}
+	logger.LogInfo("struct", "function", "second", -1)
+	logger.LogInfo("struct", "function", "third", -1)
+	logger.LogDebug("struct", "function", "fourth", -1)
+
+	if logger.Dropped() != expectedDropped {
+		t.Errorf("%d dropped records expected, got %d", expectedDropped, logger.Dropped())
+	}
+	close(receiver.gate)
+	logger.Close(context.Background())
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSuffix(receiver.String(), "\n"), "\n") {
+		got = append(got, line[strings.LastIndex(line, " ")+1:])
+	}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("incorrect records written, got %v, expecting %v", got, expected)
+	}
+}
+
+func TestAsyncDropNewest(t *testing.T) {
+	testAsyncOverflow(t, AsyncConfig{QueueSize: 2, Policy: OverflowDropNewest}, 1, []string{"first", "second", "third"})
+}
+
+func TestAsyncDropOldest(t *testing.T) {
+	testAsyncOverflow(t, AsyncConfig{QueueSize: 2, Policy: OverflowDropOldest}, 1, []string{"first", "third", "fourth"})
+}
+
+func TestAsyncDropBelowLevel(t *testing.T) {
+	testAsyncOverflow(t, AsyncConfig{QueueSize: 2, Policy: OverflowDropBelowLevel, DropLevel: LogLevelInfo}, 1, []string{"first", "second", "third"})
+}
+
+func TestAsyncBlock(t *testing.T) {
+	var receiver *gatedWriter = newGatedWriter()
+	var logger *Logger = NewLogger(LogLevelInfo, receiver, WithAsync(AsyncConfig{QueueSize: 1}))
+
+	var done chan struct{} = make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			logger.LogInfo("struct", "function", "message %d", -1, i)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("the producer must block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(receiver.gate)
+	<-done
+	logger.Close(context.Background())
+	if strings.Count(receiver.String(), "\n") != 5 || logger.Dropped() != 0 {
+		t.Errorf("no record must be dropped, got %q", receiver.String())
+	}
+}
+
+func BenchmarkLogAsync(b *testing.B) {
+	b.ReportAllocs()
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelNotice, buffer, WithAsync(AsyncConfig{Policy: OverflowDropNewest}))
+
+	for i := 0; i < b.N; i++ {
+		logger.LogNotice("struct", "func", "message", -1)
+	}
+	logger.Close(context.Background())
+}
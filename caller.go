@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// The number of frames between runtime.Callers and the caller of the Logger.
+const (
+	// runtime.Callers, captureCaller, newRecord or fillNames, log, the closure
+	// and LogXxx.
+	callerSkipLog int = 6
+	// runtime.Callers, captureCaller, newRecord or fillNames, the closure or
+	// logCtx, and FatalXxx or LogXxxCtx.
+	callerSkipDirect int = 5
+)
+
+// Caller is the location of the call to the Logger.
+type Caller struct {
+	PC   uintptr
+	File string
+	Line int
+	// Function is the fully qualified name of the function, for instance
+	// github.com/mmaFR/logger.(*Logger).LogInfo.
+	Function string
+}
+
+func (c Caller) IsZero() bool {
+	return c.PC == 0 && c.File == ""
+}
+
+// String returns the file, with its parent directory only, and the line, such
+// as db/pool.go:42.
+func (c Caller) String() string {
+	var file string = c.File
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			file = file[j+1:]
+		}
+	}
+	return file + ":" + strconv.Itoa(c.Line)
+}
+
+// CallerConfig describes the capture of the caller location.
+type CallerConfig struct {
+	// Fill replaces an empty structure by the package and receiver type of
+	// the caller, and an empty function by its name: a call from the method
+	// Query of the type *Pool of the package db gets the structure db.Pool and
+	// the function Query.
+	Fill bool
+	// Skip is the number of additional frames to skip, for the helpers
+	// wrapping a Logger.
+	Skip int
+}
+
+// WithCaller sets Record.Caller to the location of the call to the Logger,
+// rendered as the caller key by the formatters. The capture costs a stack
+// walk for each record written.
+func WithCaller(config CallerConfig) Option {
+	return func(l *Logger) {
+		l.out.caller = &config
+	}
+}
+
+// captureCaller returns the caller skip frames above runtime.Callers.
+func (o *output) captureCaller(skip int) Caller {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+o.caller.Skip, pcs[:]) == 0 {
+		return Caller{}
+	}
+	return callerFromPC(pcs[0])
+}
+
+func callerFromPC(pc uintptr) Caller {
+	var frame runtime.Frame
+	frame, _ = runtime.CallersFrames([]uintptr{pc}).Next()
+	return Caller{PC: pc, File: frame.File, Line: frame.Line, Function: frame.Function}
+}
+
+// fills tells whether the structure or the function of a record is filled
+// from its caller.
+func (o *output) fills(structure, function string) bool {
+	return o.caller != nil && o.caller.Fill && (structure == "" || function == "")
+}
+
+// fillNames returns structure and function with the empty ones filled from the
+// caller skip frames above runtime.Callers, for the level rules, the sampling
+// and the rate limit to see the names the record gets.
+func (o *output) fillNames(structure, function string, skip int) (string, string) {
+	var r Record = Record{Structure: structure, Function: function, Caller: o.captureCaller(skip)}
+	fillFromCaller(&r)
+	return r.Structure, r.Function
+}
+
+// fillFromCaller sets the empty structure and function of r from its caller.
+func fillFromCaller(r *Record) {
+	if r.Caller.Function == "" || (r.Structure != "" && r.Function != "") {
+		return
+	}
+	var structure, function string = splitFunctionName(r.Caller.Function)
+	if r.Structure == "" {
+		r.Structure = structure
+	}
+	if r.Function == "" {
+		r.Function = function
+	}
+}
+
+// splitFunctionName splits a fully qualified function name such as
+// github.com/a/db.(*Pool).Query.func1 into the package and receiver type,
+// db.Pool, and the function, Query.func1.
+func splitFunctionName(name string) (string, string) {
+	var pkgStart int = strings.LastIndexByte(name, '/') + 1
+	var dot int = strings.IndexByte(name[pkgStart:], '.')
+	if dot < 0 {
+		return name[pkgStart:], ""
+	}
+	var pkg string = name[pkgStart : pkgStart+dot]
+	var rest string = name[pkgStart+dot+1:]
+
+	if strings.HasPrefix(rest, "(") {
+		// A method with a pointer receiver: (*Pool).Query.
+		var end int = strings.IndexByte(rest, ')')
+		if end > 0 && end+2 <= len(rest) {
+			return pkg + "." + strings.TrimPrefix(rest[1:end], "*"), rest[end+2:]
+		}
+	}
+	if i := strings.IndexByte(rest, '.'); i > 0 && !strings.HasPrefix(rest[i+1:], "func") {
+		// A method with a value receiver: Pool.Query.
+		return pkg + "." + rest[:i], rest[i+1:]
+	}
+	return pkg, rest
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type callerTestService struct {
+	logger *Logger
+}
+
+func (s *callerTestService) Run() {
+	s.logger.LogInfo("", "", "run", -1)
+	func() {
+		s.logger.LogInfo("", "explicit", "closure", -1)
+	}()
+}
+
+func currentLine() string {
+	var line int
+	_, _, line, _ = runtime.Caller(1)
+	return "caller_test.go:" + strconv.Itoa(line)
+}
+
+func TestCaller(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithFormatter(new(LogfmtFormatter)), WithCaller(CallerConfig{}), WithExitFunc(func(int) {}))
+	var child *Logger = logger.With("k", "v")
+	var slogger *slog.Logger = slog.New(NewSlogHandler(logger))
+
+	var tests = []func() string{
+		func() string { logger.LogInfo("s", "f", "m", -1); return currentLine() },
+		func() string { child.LogDebug("s", "f", "m", -1); return currentLine() },
+		func() string { logger.FatalInfo("s", "f", "m", -1); return currentLine() },
+		func() string { logger.LogInfoCtx(context.Background(), "s", "f", "m", -1); return currentLine() },
+		func() string { logger.Errorf("m"); return currentLine() },
+		func() string { slogger.Info("m"); return currentLine() },
+	}
+	for i, test := range tests {
+		buffer.Reset()
+		var expected string = test()
+		if !strings.Contains(buffer.String(), "/"+expected+"\n") && !strings.Contains(buffer.String(), "/"+expected+" ") {
+			t.Errorf("test %d: expected the caller %s, got %q", i, expected, buffer.String())
+		}
+	}
+
+	logger.SetLevelRules(LevelRule{Pattern: "s", Level: LogLevelInfo})
+	buffer.Reset()
+	var expected string = func() string { logger.LogInfo("s", "f", "m", -1); return currentLine() }()
+	if !strings.Contains(buffer.String(), "/"+expected+"\n") {
+		t.Errorf("expected the caller %s with level rules, got %q", expected, buffer.String())
+	}
+}
+
+func TestCallerFill(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, buffer, WithCaller(CallerConfig{Fill: true}))
+	var service *callerTestService = &callerTestService{logger: logger}
+	service.Run()
+
+	var lines []string = strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "] logger.callerTestService -> Run: run caller=") || !strings.Contains(lines[1], "] logger.callerTestService -> explicit: closure caller=") {
+		t.Errorf("the structure and function must be filled from the caller, got %q", buffer.String())
+	}
+}
+
+func TestCallerFillRules(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, buffer, WithCaller(CallerConfig{Fill: true}))
+	var slogger *slog.Logger = slog.New(NewSlogHandler(logger))
+	logger.SetLevelRules(LevelRule{Pattern: "logger.*", Level: LogLevelDebug}, LevelRule{Pattern: "*", Level: LogLevelWarning})
+
+	logger.LogDebug("", "", "filled", -1)
+	logger.LogDebugCtx(context.Background(), "", "", "filled ctx", -1)
+	slogger.Debug("filled slog")
+	logger.LogInfo("other", "function", "other", -1)
+	var lines []string = strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "filled caller=") || !strings.Contains(lines[1], "filled ctx caller=") || !strings.Contains(lines[2], "filled slog caller=") {
+		t.Errorf("the level rules must apply to the filled names, got %q", buffer.String())
+	}
+
+	buffer.Reset()
+	logger = NewLogger(LogLevelTrace, buffer, WithCaller(CallerConfig{Fill: true}), WithSampling(SamplingConfig{Interval: time.Hour, First: 1, PerKey: true}))
+	for i := 0; i < 3; i++ {
+		logger.LogDebug("", "", "sampled", -1)
+		func() { logger.LogDebug("", "", "sampled", -1) }()
+	}
+	if n := strings.Count(buffer.String(), "\n"); n != 2 {
+		t.Errorf("the sampling keys must use the filled names, got %q", buffer.String())
+	}
+}
+
+func TestSplitFunctionName(t *testing.T) {
+	var tests = []struct {
+		name      string
+		structure string
+		function  string
+	}{
+		{"github.com/a/db.(*Pool).Query", "db.Pool", "Query"},
+		{"github.com/a/db.Pool.Query", "db.Pool", "Query"},
+		{"github.com/a/db.Pool.Query.func1", "db.Pool", "Query.func1"},
+		{"github.com/a/db.Open", "db", "Open"},
+		{"github.com/a/db.Open.func1.2", "db", "Open.func1.2"},
+		{"main.main", "main", "main"},
+		{"github.com/a/v2.(*T[...]).Get", "v2.T[...]", "Get"},
+	}
+	for _, test := range tests {
+		if structure, function := splitFunctionName(test.name); structure != test.structure || function != test.function {
+			t.Errorf("splitFunctionName(%q) returned %q, %q, expecting %q, %q", test.name, structure, function, test.structure, test.function)
+		}
+	}
+}
@@ -0,0 +1,674 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	configEnvPrefix string = "LOGGER_"
+	configEnvFile   string = "LOGGER_CONFIG"
+
+	configOutputStderr string = "stderr"
+	configOutputStdout string = "stdout"
+	configOutputNone   string = "none"
+
+	configFormatText   string = "text"
+	configFormatJSON   string = "json"
+	configFormatLogfmt string = "logfmt"
+)
+
+// Config describes a Logger built by NewLoggerFromConfig. It can be filled in
+// code, or loaded by LoadConfig, ParseConfig and ConfigFromEnv. The keys of the
+// files are the snake case names of the fields: level, rules,
+// default_structure, default_function, output, format, time_layout,
+// disable_time, disable_level, utc, rotation and sinks.
+type Config struct {
+	// Level is the verbosity, a level name or number, INFO if empty.
+	Level string
+	// Rules are level rules written as pattern=LEVEL, for instance db.*=DEBUG.
+	Rules            []string
+	DefaultStructure string
+	DefaultFunction  string
+	// OutputConfig describes the primary destination, stderr by default.
+	OutputConfig
+	// Sinks are additional destinations.
+	Sinks []SinkConfig
+}
+
+// OutputConfig describes a destination and the rendering of its records.
+type OutputConfig struct {
+	// Output is stderr, stdout, none or the path of a file opened in append
+	// mode. none is only valid for the primary destination.
+	Output string
+	// Format is text, json or logfmt, text if empty.
+	Format string
+	// TimeLayout replaces the default layout of the time of the records.
+	TimeLayout string
+	// DisableTime and DisableLevel are only supported by the text format.
+	DisableTime  bool
+	DisableLevel bool
+	// UTC renders the time of the records in UTC.
+	UTC bool
+	// Rotation makes the file output a RotatingFile, its Filename is ignored.
+	Rotation *RotatingFileConfig
+}
+
+// SinkConfig describes an additional destination of a Logger.
+type SinkConfig struct {
+	// Level is the verbosity of the sink, TRACE if empty so that it receives
+	// every record allowed by the Logger.
+	Level string
+	OutputConfig
+}
+
+// ConfigError is the error returned for an invalid configuration, Key is the
+// path of the offending key, such as sinks[1].rotation.max_age, or the name of
+// the environment variable.
+type ConfigError struct {
+	Key string
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return "logger: invalid configuration key " + e.Key + ": " + e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+func configErrorf(key string, format string, args ...any) error {
+	return &ConfigError{Key: key, Err: fmt.Errorf(format, args...)}
+}
+
+// LoadConfig reads the configuration file at path, its format is given by its
+// extension: .json, .yaml, .yml or .toml.
+func LoadConfig(path string) (Config, error) {
+	var data []byte
+	var err error
+	if data, err = os.ReadFile(path); err != nil {
+		return Config{}, err
+	}
+	return ParseConfig(data, configFormat(path))
+}
+
+// configFormat returns the format of a configuration file from its extension.
+func configFormat(path string) string {
+	var format string = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if format == "yml" {
+		return "yaml"
+	}
+	return format
+}
+
+// ParseConfig decodes a configuration in the json, yaml or toml format. Only
+// the subset of YAML and TOML needed by a configuration is supported: block
+// mappings and sequences, flow sequences of scalars, tables and arrays of
+// tables.
+func ParseConfig(data []byte, format string) (Config, error) {
+	var tree map[string]any
+	var err error
+	switch format {
+	case "json":
+		tree, err = parseJSONConfig(data)
+	case "yaml":
+		tree, err = parseYAML(data)
+	case "toml":
+		tree, err = parseTOML(data)
+	default:
+		return Config{}, errors.New("logger: unknown configuration format " + strconv.Quote(format))
+	}
+	if err != nil {
+		return Config{}, err
+	}
+	var c Config
+	if err = c.decode(tree); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+func parseJSONConfig(data []byte) (map[string]any, error) {
+	var decoder *json.Decoder = json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, errors.New("logger: invalid JSON configuration: " + err.Error())
+	}
+	var m map[string]any
+	var ok bool
+	if m, ok = tree.(map[string]any); !ok {
+		return nil, errors.New("logger: the JSON configuration must be an object")
+	}
+	return m, nil
+}
+
+// ConfigFromEnv returns the configuration read from the file named by
+// LOGGER_CONFIG, if set, overridden by the variables LOGGER_LEVEL,
+// LOGGER_RULES (comma separated), LOGGER_DEFAULT_STRUCTURE,
+// LOGGER_DEFAULT_FUNCTION, LOGGER_OUTPUT, LOGGER_FORMAT, LOGGER_TIME_LAYOUT,
+// LOGGER_DISABLE_TIME, LOGGER_DISABLE_LEVEL and LOGGER_UTC.
+func ConfigFromEnv() (Config, error) {
+	var c Config
+	var err error
+	if path := os.Getenv(configEnvFile); path != "" {
+		if c, err = LoadConfig(path); err != nil {
+			return Config{}, err
+		}
+	}
+	if err = c.applyEnv(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+func (c *Config) applyEnv(lookup func(key string) (string, bool)) error {
+	var stringVars map[string]*string = map[string]*string{
+		"LEVEL":             &c.Level,
+		"DEFAULT_STRUCTURE": &c.DefaultStructure,
+		"DEFAULT_FUNCTION":  &c.DefaultFunction,
+		"OUTPUT":            &c.Output,
+		"FORMAT":            &c.Format,
+		"TIME_LAYOUT":       &c.TimeLayout,
+	}
+	var boolVars map[string]*bool = map[string]*bool{
+		"DISABLE_TIME":  &c.DisableTime,
+		"DISABLE_LEVEL": &c.DisableLevel,
+		"UTC":           &c.UTC,
+	}
+	for name, field := range stringVars {
+		if value, ok := lookup(configEnvPrefix + name); ok {
+			*field = value
+		}
+	}
+	for name, field := range boolVars {
+		if value, ok := lookup(configEnvPrefix + name); ok {
+			var err error
+			if *field, err = strconv.ParseBool(value); err != nil {
+				return configErrorf(configEnvPrefix+name, "invalid boolean %q", value)
+			}
+		}
+	}
+	if value, ok := lookup(configEnvPrefix + "RULES"); ok {
+		c.Rules = splitList(value)
+	}
+
+	// The values are checked here so that the errors name the variables.
+	if _, ok := lookup(configEnvPrefix + "LEVEL"); ok && c.Level != "" {
+		if _, err := ParseLogLevel(c.Level); err != nil {
+			return &ConfigError{Key: configEnvPrefix + "LEVEL", Err: err}
+		}
+	}
+	if _, ok := lookup(configEnvPrefix + "RULES"); ok {
+		if _, err := parseLevelRules(c.Rules, configEnvPrefix+"RULES"); err != nil {
+			return err
+		}
+	}
+	if _, ok := lookup(configEnvPrefix + "FORMAT"); ok {
+		if err := c.OutputConfig.validateFormat(configEnvPrefix + "FORMAT"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (c *Config) decode(tree map[string]any) error {
+	for _, key := range sortedKeys(tree) {
+		var value any = tree[key]
+		var err error
+		switch key {
+		case "level":
+			c.Level, err = configLevel(key, value)
+		case "rules":
+			c.Rules, err = configStrings(key, value)
+		case "default_structure":
+			c.DefaultStructure, err = configString(key, value)
+		case "default_function":
+			c.DefaultFunction, err = configString(key, value)
+		case "sinks":
+			var items []any
+			var ok bool
+			if items, ok = value.([]any); !ok {
+				return configErrorf(key, "expected a list")
+			}
+			c.Sinks = make([]SinkConfig, len(items))
+			for i, item := range items {
+				var path string = key + "[" + strconv.Itoa(i) + "]"
+				var m map[string]any
+				if m, ok = item.(map[string]any); !ok {
+					return configErrorf(path, "expected a mapping")
+				}
+				if err = c.Sinks[i].decode(path, m); err != nil {
+					return err
+				}
+			}
+		default:
+			var known bool
+			if known, err = c.OutputConfig.decodeKey("", key, value); !known {
+				return configErrorf(key, "unknown key")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SinkConfig) decode(path string, tree map[string]any) error {
+	for _, key := range sortedKeys(tree) {
+		var err error
+		if key == "level" {
+			s.Level, err = configLevel(path+".level", tree[key])
+		} else {
+			var known bool
+			if known, err = s.OutputConfig.decodeKey(path+".", key, tree[key]); !known {
+				return configErrorf(path+"."+key, "unknown key")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *OutputConfig) decodeKey(prefix, key string, value any) (bool, error) {
+	var err error
+	var path string = prefix + key
+	switch key {
+	case "output":
+		o.Output, err = configString(path, value)
+	case "format":
+		o.Format, err = configString(path, value)
+	case "time_layout":
+		o.TimeLayout, err = configString(path, value)
+	case "disable_time":
+		o.DisableTime, err = configBool(path, value)
+	case "disable_level":
+		o.DisableLevel, err = configBool(path, value)
+	case "utc":
+		o.UTC, err = configBool(path, value)
+	case "rotation":
+		var m map[string]any
+		var ok bool
+		if m, ok = value.(map[string]any); !ok {
+			return true, configErrorf(path, "expected a mapping")
+		}
+		o.Rotation = new(RotatingFileConfig)
+		err = decodeRotation(path, m, o.Rotation)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+func decodeRotation(path string, tree map[string]any, r *RotatingFileConfig) error {
+	for _, key := range sortedKeys(tree) {
+		var value any = tree[key]
+		var keyPath string = path + "." + key
+		var err error
+		switch key {
+		case "max_size":
+			r.MaxSize, err = configInt(keyPath, value)
+		case "interval":
+			var s string
+			if s, err = configString(keyPath, value); err == nil {
+				switch strings.ToLower(s) {
+				case "", "never":
+					r.Interval = RotateNever
+				case "hourly":
+					r.Interval = RotateHourly
+				case "daily":
+					r.Interval = RotateDaily
+				default:
+					err = configErrorf(keyPath, "unknown interval %q, expected never, hourly or daily", s)
+				}
+			}
+		case "max_backups":
+			var n int64
+			n, err = configInt(keyPath, value)
+			r.MaxBackups = int(n)
+		case "max_age":
+			var s string
+			if s, err = configString(keyPath, value); err == nil {
+				if r.MaxAge, err = time.ParseDuration(s); err != nil {
+					err = &ConfigError{Key: keyPath, Err: err}
+				}
+			}
+		case "compress":
+			r.Compress, err = configBool(keyPath, value)
+		case "reopen_on_sighup":
+			r.ReopenOnSIGHUP, err = configBool(keyPath, value)
+		default:
+			return configErrorf(keyPath, "unknown key")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	var keys []string = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func configString(key string, value any) (string, error) {
+	var s string
+	var ok bool
+	if s, ok = value.(string); !ok {
+		return "", configErrorf(key, "expected a string")
+	}
+	return s, nil
+}
+
+// configLevel accepts a level name or number.
+func configLevel(key string, value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		return "", configErrorf(key, "expected a level name or number")
+	}
+}
+
+func configStrings(key string, value any) ([]string, error) {
+	var items []any
+	var ok bool
+	if items, ok = value.([]any); !ok {
+		return nil, configErrorf(key, "expected a list of strings")
+	}
+	var list []string = make([]string, len(items))
+	for i, item := range items {
+		if list[i], ok = item.(string); !ok {
+			return nil, configErrorf(key+"["+strconv.Itoa(i)+"]", "expected a string")
+		}
+	}
+	return list, nil
+}
+
+func configBool(key string, value any) (bool, error) {
+	var b bool
+	var ok bool
+	if b, ok = value.(bool); !ok {
+		return false, configErrorf(key, "expected a boolean")
+	}
+	return b, nil
+}
+
+func configInt(key string, value any) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case json.Number:
+		var n int64
+		var err error
+		if n, err = v.Int64(); err != nil {
+			return 0, configErrorf(key, "expected an integer")
+		}
+		return n, nil
+	default:
+		return 0, configErrorf(key, "expected an integer")
+	}
+}
+
+// Validate checks c, the returned error is a *ConfigError.
+func (c Config) Validate() error {
+	if c.Level != "" {
+		if _, err := ParseLogLevel(c.Level); err != nil {
+			return &ConfigError{Key: "level", Err: err}
+		}
+	}
+	if _, err := parseLevelRules(c.Rules, "rules"); err != nil {
+		return err
+	}
+	if err := c.OutputConfig.validate("", true); err != nil {
+		return err
+	}
+	for i, s := range c.Sinks {
+		var prefix string = "sinks[" + strconv.Itoa(i) + "]."
+		if s.Level != "" {
+			if _, err := ParseLogLevel(s.Level); err != nil {
+				return &ConfigError{Key: prefix + "level", Err: err}
+			}
+		}
+		if err := s.OutputConfig.validate(prefix, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o OutputConfig) validate(prefix string, primary bool) error {
+	switch o.Output {
+	case "":
+		if !primary {
+			return configErrorf(prefix+"output", "missing output")
+		}
+	case configOutputNone:
+		if !primary {
+			return configErrorf(prefix+"output", "none is only valid for the primary output")
+		}
+	}
+	if err := o.validateFormat(prefix + "format"); err != nil {
+		return err
+	}
+	if o.Format != "" && o.Format != configFormatText {
+		if o.DisableTime {
+			return configErrorf(prefix+"disable_time", "only supported by the text format")
+		}
+		if o.DisableLevel {
+			return configErrorf(prefix+"disable_level", "only supported by the text format")
+		}
+	}
+	if o.Rotation != nil && !o.isFile() {
+		return configErrorf(prefix+"rotation", "requires a file output")
+	}
+	return nil
+}
+
+func (o OutputConfig) validateFormat(key string) error {
+	switch o.Format {
+	case "", configFormatText, configFormatJSON, configFormatLogfmt:
+		return nil
+	default:
+		return configErrorf(key, "unknown format %q, expected text, json or logfmt", o.Format)
+	}
+}
+
+func (o OutputConfig) isFile() bool {
+	switch o.Output {
+	case "", configOutputNone, configOutputStderr, configOutputStdout:
+		return false
+	default:
+		return true
+	}
+}
+
+func (o OutputConfig) formatter() Formatter {
+	var f Formatter
+	switch o.Format {
+	case configFormatJSON:
+		f = &JSONFormatter{TimeLayout: o.TimeLayout}
+	case configFormatLogfmt:
+		f = &LogfmtFormatter{TimeLayout: o.TimeLayout}
+	default:
+		f = &TextFormatter{DisableTime: o.DisableTime, DisableLevel: o.DisableLevel, TimeLayout: o.TimeLayout}
+	}
+	if o.UTC {
+		return utcFormatter{f}
+	}
+	return f
+}
+
+// open returns the writer of o, or nil for none, and the closer of the files
+// it opened.
+func (o OutputConfig) open() (io.Writer, io.Closer, error) {
+	switch o.Output {
+	case configOutputNone:
+		return nil, nil, nil
+	case "", configOutputStderr:
+		return os.Stderr, nil, nil
+	case configOutputStdout:
+		return os.Stdout, nil, nil
+	}
+	if o.Rotation != nil {
+		var config RotatingFileConfig = *o.Rotation
+		config.Filename = o.Output
+		var w *RotatingFile
+		var err error
+		if w, err = NewRotatingFile(config); err != nil {
+			return nil, nil, err
+		}
+		return w, w, nil
+	}
+	var file *os.File
+	var err error
+	if file, err = os.OpenFile(o.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return nil, nil, err
+	}
+	return file, file, nil
+}
+
+// utcFormatter renders the time of the records in UTC.
+type utcFormatter struct {
+	Formatter
+}
+
+func (f utcFormatter) Format(r *Record) []byte {
+	var utc Record = *r
+	utc.Time = r.Time.UTC()
+	return f.Formatter.Format(&utc)
+}
+
+func parseLevelRules(rules []string, key string) ([]LevelRule, error) {
+	var parsed []LevelRule = make([]LevelRule, 0, len(rules))
+	for i, rule := range rules {
+		var ruleKey string = key + "[" + strconv.Itoa(i) + "]"
+		var sep int = strings.LastIndexByte(rule, '=')
+		if sep < 0 {
+			return nil, configErrorf(ruleKey, "expected pattern=LEVEL, got %q", rule)
+		}
+		var level LogLevel
+		var err error
+		if level, err = ParseLogLevel(rule[sep+1:]); err != nil {
+			return nil, &ConfigError{Key: ruleKey, Err: err}
+		}
+		parsed = append(parsed, LevelRule{Pattern: strings.TrimSpace(rule[:sep]), Level: level})
+	}
+	return parsed, nil
+}
+
+// NewLoggerFromConfig validates c and builds the Logger it describes. The
+// files it opens are closed by the Close method of the Logger.
+func NewLoggerFromConfig(c Config) (*Logger, error) {
+	var l *Logger = NewLogger(LogLevelInfo, nil)
+	if err := l.ApplyConfig(c); err != nil {
+		return nil, err
+	}
+	if c.DefaultStructure != "" {
+		l.SetDefaultStructure(c.DefaultStructure)
+	}
+	l.SetDefaultFunction(c.DefaultFunction)
+	return l, nil
+}
+
+// levels returns the verbosity and the level rules of c, which must be valid.
+func (c Config) levels() (LogLevel, []LevelRule) {
+	var level LogLevel = LogLevelInfo
+	if c.Level != "" {
+		level, _ = ParseLogLevel(c.Level)
+	}
+	var rules []LevelRule
+	rules, _ = parseLevelRules(c.Rules, "rules")
+	return level, rules
+}
+
+// openSinks opens the destinations of c, which must be valid. The primary sink
+// is nil when the output is none.
+func (c Config) openSinks() (*sink, []*sink, []io.Closer, error) {
+	var sinks []*sink
+	var closers []io.Closer
+	var closeAll = func() {
+		for _, closer := range closers {
+			closer.Close()
+		}
+	}
+
+	var primary *sink
+	var dst io.Writer
+	var closer io.Closer
+	var err error
+	if dst, closer, err = c.OutputConfig.open(); err != nil {
+		return nil, nil, nil, &ConfigError{Key: "output", Err: err}
+	}
+	if closer != nil {
+		closers = append(closers, closer)
+	}
+	if dst != nil {
+		primary = newSink(dst, LogLevelTrace, c.OutputConfig.formatter())
+		sinks = append(sinks, primary)
+	}
+	for i, s := range c.Sinks {
+		var level LogLevel = LogLevelTrace
+		if s.Level != "" {
+			level, _ = ParseLogLevel(s.Level)
+		}
+		if dst, closer, err = s.OutputConfig.open(); err != nil {
+			closeAll()
+			return nil, nil, nil, &ConfigError{Key: "sinks[" + strconv.Itoa(i) + "].output", Err: err}
+		}
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+		sinks = append(sinks, newSink(dst, level, s.OutputConfig.formatter()))
+	}
+	return primary, sinks, closers, nil
+}
+
+// closeWriters closes the destinations created by the Logger, such as the
+// files opened by NewLoggerFromConfig and ApplyConfig.
+func (o *output) closeWriters() error {
+	o.mu.Lock()
+	var closers []io.Closer = append(o.configClosers, o.closers...)
+	o.closers, o.configClosers = nil, nil
+	o.mu.Unlock()
+
+	var err error
+	for _, closer := range closers {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
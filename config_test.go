@@ -0,0 +1,235 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testConfig Config = Config{
+	Level:            "DEBUG",
+	Rules:            []string{"db.*=TRACE", "*=INFO"},
+	DefaultStructure: "app",
+	OutputConfig:     OutputConfig{Output: "stdout", Format: "json", TimeLayout: "15:04:05", UTC: true},
+	Sinks: []SinkConfig{
+		{Level: "ERROR", OutputConfig: OutputConfig{Output: "/var/log/app.log", DisableTime: true, Rotation: &RotatingFileConfig{MaxSize: 1048576, Interval: RotateDaily, MaxBackups: 3, MaxAge: 168 * time.Hour, Compress: true}}},
+		{Level: "4", OutputConfig: OutputConfig{Output: "stderr", Format: "logfmt"}},
+	},
+}
+
+const testConfigJSON string = `{
+	"level": "DEBUG",
+	"rules": ["db.*=TRACE", "*=INFO"],
+	"default_structure": "app",
+	"output": "stdout",
+	"format": "json",
+	"time_layout": "15:04:05",
+	"utc": true,
+	"sinks": [
+		{
+			"level": "ERROR",
+			"output": "/var/log/app.log",
+			"disable_time": true,
+			"rotation": {"max_size": 1048576, "interval": "daily", "max_backups": 3, "max_age": "168h", "compress": true}
+		},
+		{"level": 4, "output": "stderr", "format": "logfmt"}
+	]
+}`
+
+const testConfigYAML string = `
+# The service configuration.
+level: DEBUG
+rules: ["db.*=TRACE", "*=INFO"]
+default_structure: app
+output: stdout
+format: json
+time_layout: "15:04:05"
+utc: true
+sinks:
+- level: ERROR
+  output: /var/log/app.log
+  disable_time: true
+  rotation:
+    max_size: 1048576
+    interval: daily # rotated at midnight
+    max_backups: 3
+    max_age: 168h
+    compress: true
+- level: 4
+  output: 'stderr'
+  format: logfmt
+`
+
+const testConfigTOML string = `
+# The service configuration.
+level = "DEBUG"
+rules = [
+	"db.*=TRACE",
+	"*=INFO", # the default
+]
+default_structure = "app"
+output = "stdout"
+format = "json"
+time_layout = "15:04:05"
+utc = true
+
+[[sinks]]
+level = "ERROR"
+output = "/var/log/app.log"
+disable_time = true
+
+[sinks.rotation]
+max_size = 1_048_576
+interval = 'daily'
+max_backups = 3
+max_age = "168h"
+compress = true
+
+[[sinks]]
+level = 4
+output = "stderr"
+format = "logfmt"
+`
+
+func TestParseConfig(t *testing.T) {
+	for format, data := range map[string]string{"json": testConfigJSON, "yaml": testConfigYAML, "toml": testConfigTOML} {
+		var c Config
+		var err error
+		if c, err = ParseConfig([]byte(data), format); err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+		if !reflect.DeepEqual(c, testConfig) {
+			t.Errorf("%s: got %+v, expecting %+v", format, c, testConfig)
+		}
+		if err = c.Validate(); err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	var tests = []struct {
+		format string
+		data   string
+		key    string
+	}{
+		{"json", `{"levle": "INFO"}`, "levle"},
+		{"json", `{"level": true}`, "level"},
+		{"json", `{"sinks": [{"output": "stdout"}, {"output": "stderr", "rotation": {"max_age": "1 week"}}]}`, "sinks[1].rotation.max_age"},
+		{"yaml", "sinks:\n  - output: stdout\n    colour: true\n", "sinks[0].colour"},
+		{"yaml", "rules:\n  - db.*=DEBUG\n  - 3\n", "rules[1]"},
+		{"toml", "[rotation]\ninterval = \"weekly\"\n", "rotation.interval"},
+		{"toml", "[[sinks]]\nlevel = \"INFO\"\nmax_size = 10\n", "sinks[0].max_size"},
+	}
+	for _, test := range tests {
+		var err error
+		var configErr *ConfigError
+		if _, err = ParseConfig([]byte(test.data), test.format); !errors.As(err, &configErr) || configErr.Key != test.key {
+			t.Errorf("%s %q: expected an error on the key %s, got %v", test.format, test.data, test.key, err)
+		}
+	}
+
+	var validations = []struct {
+		config Config
+		key    string
+	}{
+		{Config{Level: "VERBOSE"}, "level"},
+		{Config{Rules: []string{"db.*"}}, "rules[0]"},
+		{Config{Rules: []string{"db.*=LOUD"}}, "rules[0]"},
+		{Config{OutputConfig: OutputConfig{Format: "xml"}}, "format"},
+		{Config{OutputConfig: OutputConfig{Format: "json", DisableLevel: true}}, "disable_level"},
+		{Config{OutputConfig: OutputConfig{Output: "stdout", Rotation: &RotatingFileConfig{}}}, "rotation"},
+		{Config{Sinks: []SinkConfig{{Level: "INFO"}}}, "sinks[0].output"},
+		{Config{Sinks: []SinkConfig{{Level: "LOUD", OutputConfig: OutputConfig{Output: "stdout"}}}}, "sinks[0].level"},
+	}
+	for _, test := range validations {
+		var err error
+		var configErr *ConfigError
+		if err = test.config.Validate(); !errors.As(err, &configErr) || configErr.Key != test.key {
+			t.Errorf("%+v: expected an error on the key %s, got %v", test.config, test.key, err)
+		}
+	}
+}
+
+func TestConfigEnv(t *testing.T) {
+	var env map[string]string = map[string]string{
+		"LOGGER_LEVEL":        "trace",
+		"LOGGER_RULES":        "db.*=DEBUG, http=WARNING",
+		"LOGGER_FORMAT":       "logfmt",
+		"LOGGER_DISABLE_TIME": "false",
+		"LOGGER_UTC":          "1",
+	}
+	var lookup = func(key string) (string, bool) {
+		var value string
+		var ok bool
+		value, ok = env[key]
+		return value, ok
+	}
+	var c Config = Config{Level: "INFO", OutputConfig: OutputConfig{Output: "stdout"}}
+	if err := c.applyEnv(lookup); err != nil {
+		t.Fatal(err)
+	}
+	var expected Config = Config{Level: "trace", Rules: []string{"db.*=DEBUG", "http=WARNING"}, OutputConfig: OutputConfig{Output: "stdout", Format: "logfmt", UTC: true}}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("got %+v, expecting %+v", c, expected)
+	}
+
+	for key, value := range map[string]string{"LOGGER_LEVEL": "LOUD", "LOGGER_UTC": "sometimes", "LOGGER_FORMAT": "xml"} {
+		env = map[string]string{key: value}
+		var configErr *ConfigError
+		if err := new(Config).applyEnv(lookup); !errors.As(err, &configErr) || configErr.Key != key {
+			t.Errorf("expected an error on %s, got %v", key, err)
+		}
+	}
+}
+
+func TestNewLoggerFromConfig(t *testing.T) {
+	var dir string = t.TempDir()
+	var c Config = Config{
+		Level:            "INFO",
+		Rules:            []string{"db=DEBUG"},
+		DefaultStructure: "app",
+		OutputConfig:     OutputConfig{Output: filepath.Join(dir, "app.log"), Format: "logfmt", TimeLayout: "2006"},
+		Sinks: []SinkConfig{
+			{Level: "ERROR", OutputConfig: OutputConfig{Output: filepath.Join(dir, "error.log"), DisableTime: true, Rotation: &RotatingFileConfig{MaxSize: 1 << 20}}},
+		},
+	}
+	var logger *Logger
+	var err error
+	if logger, err = NewLoggerFromConfig(c); err != nil {
+		t.Fatal(err)
+	}
+	logger.LogDebug("db", "Query", "query", -1)
+	logger.LogDebug("http", "Serve", "serve", -1)
+	logger.LogError("http", "Serve", "failure", -1)
+	if err = logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var data []byte
+	data, _ = os.ReadFile(filepath.Join(dir, "app.log"))
+	var lines []string = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "msg=query") || !strings.HasPrefix(lines[1], "ts="+time.Now().Format("2006")+" level=ERROR") {
+		t.Errorf("unexpected primary output %q", data)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "error.log"))
+	if string(data) != "[ERROR   ] http -> Serve: failure\n" {
+		t.Errorf("unexpected sink output %q", data)
+	}
+	if logger.defaultStructure != "app" {
+		t.Errorf("the default structure must be set, got %q", logger.defaultStructure)
+	}
+
+	c.Sinks[0].Output = filepath.Join(dir, "missing", "dir", "file.log")
+	c.Sinks[0].Rotation = nil
+	var configErr *ConfigError
+	if _, err = NewLoggerFromConfig(c); !errors.As(err, &configErr) || configErr.Key != "sinks[0].output" {
+		t.Errorf("expected an error on sinks[0].output, got %v", err)
+	}
+}
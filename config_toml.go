@@ -0,0 +1,355 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML decodes the subset of TOML used by the configuration files: key
+// value pairs with dotted keys, tables, arrays of tables, basic and literal
+// strings, integers, booleans, arrays and inline tables. Multi-line strings,
+// floats and dates are not supported.
+func parseTOML(data []byte) (map[string]any, error) {
+	var p *tomlParser = &tomlParser{data: string(data), line: 1}
+	var root map[string]any = map[string]any{}
+	var current map[string]any = root
+	var err error
+
+	for {
+		p.skipBlank(true)
+		if p.pos >= len(p.data) {
+			return root, nil
+		}
+		if p.data[p.pos] == '[' {
+			if current, err = p.parseHeader(root); err != nil {
+				return nil, err
+			}
+		} else if err = p.parseKeyValue(current); err != nil {
+			return nil, err
+		}
+		if err = p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type tomlParser struct {
+	data string
+	pos  int
+	line int
+}
+
+func (p *tomlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("logger: invalid TOML configuration: line %d: "+format, append([]any{p.line}, args...)...)
+}
+
+// skipBlank skips the spaces and the comments, and the new lines when
+// newlines is set.
+func (p *tomlParser) skipBlank(newlines bool) {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\r':
+			p.pos++
+		case '\n':
+			if !newlines {
+				return
+			}
+			p.line++
+			p.pos++
+		case '#':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) endOfLine() error {
+	p.skipBlank(false)
+	if p.pos < len(p.data) && p.data[p.pos] != '\n' {
+		return p.errorf("unexpected %q after the value", p.data[p.pos])
+	}
+	return nil
+}
+
+// parseHeader parses a [table] or [[array]] header and returns the table
+// receiving the following key value pairs.
+func (p *tomlParser) parseHeader(root map[string]any) (map[string]any, error) {
+	var array bool = strings.HasPrefix(p.data[p.pos:], "[[")
+	if array {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+	var keys []string
+	var err error
+	if keys, err = p.parseKey(); err != nil {
+		return nil, err
+	}
+	var closing string = "]"
+	if array {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.data[p.pos:], closing) {
+		return nil, p.errorf("expected %s", closing)
+	}
+	p.pos += len(closing)
+
+	var table map[string]any
+	if table, err = p.descend(root, keys[:len(keys)-1]); err != nil {
+		return nil, err
+	}
+	var last string = keys[len(keys)-1]
+	if !array {
+		return p.descend(table, []string{last})
+	}
+	var list []any
+	switch existing := table[last].(type) {
+	case nil:
+	case []any:
+		list = existing
+	default:
+		return nil, p.errorf("the key %q is not an array of tables", last)
+	}
+	var element map[string]any = map[string]any{}
+	table[last] = append(list, element)
+	return element, nil
+}
+
+// descend returns the table at keys below table, creating the missing ones.
+// An array of tables stands for its last element.
+func (p *tomlParser) descend(table map[string]any, keys []string) (map[string]any, error) {
+	for _, key := range keys {
+		switch existing := table[key].(type) {
+		case nil:
+			var child map[string]any = map[string]any{}
+			table[key] = child
+			table = child
+		case map[string]any:
+			table = existing
+		case []any:
+			var ok bool
+			if len(existing) == 0 {
+				return nil, p.errorf("the key %q is not a table", key)
+			}
+			if table, ok = existing[len(existing)-1].(map[string]any); !ok {
+				return nil, p.errorf("the key %q is not a table", key)
+			}
+		default:
+			return nil, p.errorf("the key %q is not a table", key)
+		}
+	}
+	return table, nil
+}
+
+func (p *tomlParser) parseKeyValue(table map[string]any) error {
+	var keys []string
+	var err error
+	if keys, err = p.parseKey(); err != nil {
+		return err
+	}
+	if p.pos >= len(p.data) || p.data[p.pos] != '=' {
+		return p.errorf("expected = after the key")
+	}
+	p.pos++
+	p.skipBlank(false)
+	var value any
+	if value, err = p.parseValue(); err != nil {
+		return err
+	}
+	if table, err = p.descend(table, keys[:len(keys)-1]); err != nil {
+		return err
+	}
+	var last string = keys[len(keys)-1]
+	if _, exists := table[last]; exists {
+		return p.errorf("duplicate key %q", last)
+	}
+	table[last] = value
+	return nil
+}
+
+// parseKey parses a bare, quoted or dotted key and the spaces following it.
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		p.skipBlank(false)
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("expected a key")
+		}
+		var key string
+		var err error
+		switch c := p.data[p.pos]; {
+		case c == '"' || c == '\'':
+			if key, err = p.parseString(); err != nil {
+				return nil, err
+			}
+		default:
+			var start int = p.pos
+			for p.pos < len(p.data) && isTOMLBareKeyChar(p.data[p.pos]) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf("expected a key")
+			}
+			key = p.data[start:p.pos]
+		}
+		keys = append(keys, key)
+		p.skipBlank(false)
+		if p.pos >= len(p.data) || p.data[p.pos] != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) parseValue() (any, error) {
+	if p.pos >= len(p.data) {
+		return nil, p.errorf("expected a value")
+	}
+	switch c := p.data[p.pos]; {
+	case c == '"' || c == '\'':
+		return p.parseString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	}
+
+	var start int = p.pos
+	for p.pos < len(p.data) && !strings.ContainsRune(" \t\r\n#,]}", rune(p.data[p.pos])) {
+		p.pos++
+	}
+	var text string = p.data[start:p.pos]
+	switch text {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if n, ok := parseTOMLInteger(text); ok {
+		return n, nil
+	}
+	return nil, p.errorf("unsupported value %q", text)
+}
+
+// parseTOMLInteger parses a decimal integer without leading zero, or an
+// unsigned hexadecimal, octal or binary one prefixed with 0x, 0o or 0b. The
+// underscores are only allowed between digits.
+func parseTOMLInteger(text string) (int64, bool) {
+	var base int = 10
+	var digits string = text
+	switch {
+	case strings.HasPrefix(text, "0x"):
+		base, digits = 16, text[2:]
+	case strings.HasPrefix(text, "0o"):
+		base, digits = 8, text[2:]
+	case strings.HasPrefix(text, "0b"):
+		base, digits = 2, text[2:]
+	}
+	var unsigned string = digits
+	if base == 10 && (strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-")) {
+		unsigned = digits[1:]
+	}
+	if unsigned == "" || unsigned[0] == '_' || unsigned[0] == '+' || unsigned[0] == '-' || strings.HasSuffix(unsigned, "_") || strings.Contains(unsigned, "__") {
+		return 0, false
+	}
+	if base == 10 && len(unsigned) > 1 && unsigned[0] == '0' {
+		return 0, false
+	}
+	var n int64
+	var err error
+	if n, err = strconv.ParseInt(strings.ReplaceAll(digits, "_", ""), base, 64); err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func (p *tomlParser) parseString() (string, error) {
+	var quote byte = p.data[p.pos]
+	if strings.HasPrefix(p.data[p.pos:], strings.Repeat(string(quote), 3)) {
+		return "", p.errorf("multi-line strings are not supported")
+	}
+	var start int = p.pos
+	p.pos++
+	for p.pos < len(p.data) && p.data[p.pos] != quote && p.data[p.pos] != '\n' {
+		if quote == '"' && p.data[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.data) || p.data[p.pos] != quote {
+		return "", p.errorf("unterminated string")
+	}
+	p.pos++
+	if quote == '\'' {
+		return p.data[start+1 : p.pos-1], nil
+	}
+	var s string
+	var err error
+	if s, err = strconv.Unquote(p.data[start:p.pos]); err != nil {
+		return "", p.errorf("invalid string %s", p.data[start:p.pos])
+	}
+	return s, nil
+}
+
+func (p *tomlParser) parseArray() (any, error) {
+	p.pos++
+	var list []any = []any{}
+	for {
+		p.skipBlank(true)
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			return list, nil
+		}
+		var value any
+		var err error
+		if value, err = p.parseValue(); err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+		p.skipBlank(true)
+		if p.pos < len(p.data) && p.data[p.pos] == ',' {
+			p.pos++
+		} else if p.pos >= len(p.data) || p.data[p.pos] != ']' {
+			return nil, p.errorf("expected , or ] in an array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (any, error) {
+	p.pos++
+	var table map[string]any = map[string]any{}
+	p.skipBlank(false)
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		return table, nil
+	}
+	for {
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipBlank(false)
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unterminated inline table")
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return table, nil
+		default:
+			return nil, p.errorf("expected , or } in an inline table")
+		}
+	}
+}
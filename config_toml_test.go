@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	var data string = `
+a = "escaped \"quote\" # not a comment"
+b = 'C:\path'
+"c d".e = -12
+f = { g = true, h = [1, 0x10] }
+
+[t.u]
+v = false
+
+[[list]]
+x = 1
+[[list]]
+x = 2
+[list.sub]
+y = "z"
+`
+	var expected map[string]any = map[string]any{
+		"a":   "escaped \"quote\" # not a comment",
+		"b":   `C:\path`,
+		"c d": map[string]any{"e": int64(-12)},
+		"f":   map[string]any{"g": true, "h": []any{int64(1), int64(16)}},
+		"t":   map[string]any{"u": map[string]any{"v": false}},
+		"list": []any{
+			map[string]any{"x": int64(1)},
+			map[string]any{"x": int64(2), "sub": map[string]any{"y": "z"}},
+		},
+	}
+	var got map[string]any
+	var err error
+	if got, err = parseTOML([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %#v, expecting %#v", got, expected)
+	}
+
+	var integers map[string]int64 = map[string]int64{"0": 0, "+0": 0, "-17": -17, "1_000": 1000, "0x1F": 31, "0o17": 15, "0b101": 5}
+	for text, n := range integers {
+		if value, ok := parseTOMLInteger(text); !ok || value != n {
+			t.Errorf("parseTOMLInteger(%q) returned %d, %t, expecting %d", text, value, ok, n)
+		}
+	}
+
+	for _, invalid := range []string{
+		"a = 1\na = 2\n",
+		"a = 1 b = 2\n",
+		"a = \"\"\"multi\"\"\"\n",
+		"a = 1.5\n",
+		"a = [1, 2\n",
+		"a = \"unterminated\n",
+		"a = 1\n[[a]]\n",
+		"[t\n",
+		"a = 010\n",
+		"a = -01\n",
+		"a = 1__0\n",
+		"a = 1_\n",
+		"a = 0x_1\n",
+		"a = -0x1\n",
+	} {
+		if _, err = parseTOML([]byte(invalid)); err == nil {
+			t.Errorf("an error is expected for %q", invalid)
+		}
+	}
+}
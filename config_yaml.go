@@ -0,0 +1,288 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseYAML decodes the subset of YAML used by the configuration files: block
+// mappings and sequences, flow sequences of scalars, plain and quoted scalars
+// and comments. Anchors, multi-line scalars and flow mappings are not
+// supported.
+func parseYAML(data []byte) (map[string]any, error) {
+	var lines []yamlLine
+	for i, text := range strings.Split(string(data), "\n") {
+		text = strings.TrimRight(stripYAMLComment(strings.TrimRight(text, "\r")), " \t")
+		var trimmed string = strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" || trimmed == "..." {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, yamlErrorf(i+1, "tabs are not allowed in the indentation")
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return map[string]any{}, nil
+	}
+
+	var p *yamlParser = &yamlParser{lines: lines}
+	var value any
+	var err error
+	if value, err = p.parseBlock(lines[0].indent); err != nil {
+		return nil, err
+	}
+	if p.pos < len(lines) {
+		return nil, yamlErrorf(lines[p.pos].number, "unexpected indentation")
+	}
+	var m map[string]any
+	var ok bool
+	if m, ok = value.(map[string]any); !ok {
+		return nil, yamlErrorf(lines[0].number, "the document must be a mapping")
+	}
+	return m, nil
+}
+
+func yamlErrorf(line int, format string, args ...any) error {
+	return fmt.Errorf("logger: invalid YAML configuration: line %d: "+format, append([]any{line}, args...)...)
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) parseBlock(indent int) (any, error) {
+	if isYAMLSequenceItem(p.lines[p.pos].text) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func (p *yamlParser) parseMapping(indent int) (any, error) {
+	var m map[string]any = map[string]any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		var line yamlLine = p.lines[p.pos]
+		if isYAMLSequenceItem(line.text) {
+			return nil, yamlErrorf(line.number, "unexpected sequence item in a mapping")
+		}
+		var key, rest string
+		var err error
+		if key, rest, err = splitYAMLKey(line); err != nil {
+			return nil, err
+		}
+		if _, exists := m[key]; exists {
+			return nil, yamlErrorf(line.number, "duplicate key %q", key)
+		}
+		p.pos++
+
+		var value any
+		switch {
+		case rest != "":
+			value, err = parseYAMLScalar(line.number, rest)
+		case p.pos < len(p.lines) && p.lines[p.pos].indent > indent:
+			value, err = p.parseBlock(p.lines[p.pos].indent)
+		case p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSequenceItem(p.lines[p.pos].text):
+			// A sequence may have the indentation of its key.
+			value, err = p.parseSequence(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[key] = value
+	}
+	return m, nil
+}
+
+func (p *yamlParser) parseSequence(indent int) (any, error) {
+	var list []any = []any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSequenceItem(p.lines[p.pos].text) {
+		var line yamlLine = p.lines[p.pos]
+		var rest string = strings.TrimLeft(line.text[1:], " ")
+		var value any
+		var err error
+		switch {
+		case rest == "":
+			p.pos++
+			if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+				value, err = p.parseBlock(p.lines[p.pos].indent)
+			}
+		case isYAMLSequenceItem(rest) || isYAMLMappingEntry(rest):
+			// The item is a block starting on the line of the dash, the line is
+			// parsed again as if the dash was a space.
+			p.lines[p.pos] = yamlLine{number: line.number, indent: indent + len(line.text) - len(rest), text: rest}
+			value, err = p.parseBlock(p.lines[p.pos].indent)
+		default:
+			p.pos++
+			value, err = parseYAMLScalar(line.number, rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isYAMLMappingEntry(text string) bool {
+	return yamlKeyEnd(text) >= 0
+}
+
+// yamlKeyEnd returns the index of the colon ending the key of text, or -1.
+func yamlKeyEnd(text string) int {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case i == 0 && (c == '"' || c == '\''):
+			quote = c
+		case c == '[' || c == '{':
+			if i == 0 {
+				return -1
+			}
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+func splitYAMLKey(line yamlLine) (string, string, error) {
+	var end int = yamlKeyEnd(line.text)
+	if end < 0 {
+		return "", "", yamlErrorf(line.number, "expected a key")
+	}
+	var key string = strings.TrimSpace(line.text[:end])
+	var rest string = strings.TrimSpace(line.text[end+1:])
+	if len(key) > 0 && (key[0] == '"' || key[0] == '\'') {
+		var value any
+		var err error
+		if value, err = parseYAMLScalar(line.number, key); err != nil {
+			return "", "", err
+		}
+		key = value.(string)
+	}
+	return key, rest, nil
+}
+
+// stripYAMLComment removes a comment, starting with a # at the beginning of
+// the line or after a space, outside of quotes.
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t:-[,", rune(text[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+func parseYAMLScalar(line int, text string) (any, error) {
+	switch {
+	case text[0] == '"':
+		var s string
+		var err error
+		if s, err = strconv.Unquote(text); err != nil {
+			return nil, yamlErrorf(line, "invalid double quoted string %s", text)
+		}
+		return s, nil
+	case text[0] == '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return nil, yamlErrorf(line, "invalid single quoted string %s", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case text[0] == '[':
+		return parseYAMLFlowSequence(line, text)
+	case text == "{}":
+		return map[string]any{}, nil
+	case text[0] == '{':
+		return nil, yamlErrorf(line, "flow mappings are not supported")
+	case text[0] == '&' || text[0] == '*' || text[0] == '|' || text[0] == '>':
+		return nil, yamlErrorf(line, "anchors, aliases and multi-line scalars are not supported")
+	}
+	switch text {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	}
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n, nil
+	}
+	return text, nil
+}
+
+func parseYAMLFlowSequence(line int, text string) (any, error) {
+	if text[len(text)-1] != ']' {
+		return nil, yamlErrorf(line, "unterminated flow sequence")
+	}
+	var list []any = []any{}
+	var inner string = strings.TrimSpace(text[1 : len(text)-1])
+	if inner == "" {
+		return list, nil
+	}
+	var quote byte
+	var start int
+	for i := 0; i <= len(inner); i++ {
+		if i < len(inner) {
+			var c byte = inner[i]
+			switch {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				} else if c == '\\' && quote == '"' {
+					i++
+				}
+				continue
+			case c == '"' || c == '\'':
+				quote = c
+				continue
+			case c == '[' || c == '{':
+				return nil, yamlErrorf(line, "nested flow collections are not supported")
+			case c != ',':
+				continue
+			}
+		}
+		var item string = strings.TrimSpace(inner[start:i])
+		if item == "" {
+			return nil, yamlErrorf(line, "empty item in a flow sequence")
+		}
+		var value any
+		var err error
+		if value, err = parseYAMLScalar(line, item); err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+		start = i + 1
+	}
+	return list, nil
+}
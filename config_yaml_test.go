@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	var data string = `
+a: "quoted # not a comment"
+b: 'it''s'
+"c d": -12
+e:
+  - x
+  -
+    nested: true
+  - - 1
+    - 2
+f: []
+g: ~
+h: http://example.com:8080/path
+`
+	var expected map[string]any = map[string]any{
+		"a":   "quoted # not a comment",
+		"b":   "it's",
+		"c d": int64(-12),
+		"e":   []any{"x", map[string]any{"nested": true}, []any{int64(1), int64(2)}},
+		"f":   []any{},
+		"g":   nil,
+		"h":   "http://example.com:8080/path",
+	}
+	var got map[string]any
+	var err error
+	if got, err = parseYAML([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %#v, expecting %#v", got, expected)
+	}
+
+	for _, invalid := range []string{
+		"a: 1\na: 2\n",
+		"a: 1\n  b: 2\n",
+		"- a\n",
+		"a: {b: 1}\n",
+		"a: |\n  text\n",
+		"a: [1, [2]]\n",
+		"a: \"unterminated\n",
+	} {
+		if _, err = parseYAML([]byte(invalid)); err == nil {
+			t.Errorf("an error is expected for %q", invalid)
+		}
+	}
+}
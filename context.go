@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"context"
+)
+
+// ContextExtractor returns the fields to add to a record logged with ctx, for
+// instance a request id stored in the context by a middleware.
+type ContextExtractor func(ctx context.Context) []Field
+
+// ContextValueExtractor returns a ContextExtractor adding the value stored in
+// the context under key as the field name, when the value is not nil.
+func ContextValueExtractor(key any, name string) ContextExtractor {
+	return func(ctx context.Context) []Field {
+		var value any = ctx.Value(key)
+		if value == nil {
+			return nil
+		}
+		return []Field{{Key: name, Value: value}}
+	}
+}
+
+// AddContextExtractor registers an extractor run for every record logged with
+// a context. The extractors are shared with the derived loggers.
+func (l *Logger) AddContextExtractor(e ContextExtractor) {
+	l.out.extractorsMu.Lock()
+	l.out.extractors = append(l.out.extractors, e)
+	l.out.extractorsMu.Unlock()
+}
+
+// WithContextExtractor registers an extractor, see AddContextExtractor.
+func WithContextExtractor(e ContextExtractor) Option {
+	return func(l *Logger) {
+		l.AddContextExtractor(e)
+	}
+}
+
+// WithContext returns a derived Logger whose records are logged with ctx, the
+// context extractors run on each record.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	var child *Logger = l.derive()
+	child.ctx = ctx
+	return child
+}
+
+// Context returns the context given to WithContext, nil otherwise.
+func (l *Logger) Context() context.Context {
+	return l.ctx
+}
+
+// contextFields appends the fields extracted from ctx to fields, without
+// modifying the backing array of fields.
+func (o *output) contextFields(ctx context.Context, fields []Field) []Field {
+	if ctx == nil {
+		return fields
+	}
+	o.extractorsMu.RLock()
+	defer o.extractorsMu.RUnlock()
+	if len(o.extractors) == 0 {
+		return fields
+	}
+
+	var extracted []Field = fields[:len(fields):len(fields)]
+	for _, extractor := range o.extractors {
+		extracted = append(extracted, extractor(ctx)...)
+	}
+	return extracted
+}
+
+func (l *Logger) logCtx(ctx context.Context, level LogLevel, structure, function, msg string, id int, vars []any) {
+	if l.out.fills(structure, function) {
+		structure, function = l.out.fillNames(structure, function, callerSkipDirect)
+	}
+	if !l.out.funcs.Load().enabled(level, structure, function) {
+		return
+	}
+	if l.out.drop(level, structure, function, msg) {
+		return
+	}
+	var r Record = l.newRecord(ctx, level, structure, function, msg, id, vars, callerSkipDirect)
+	l.out.write(&r)
+}
+
+func (l *Logger) LogEmergeCtx(ctx context.Context, structure, function, msg string, id int, vars ...any) {
+	l.logCtx(ctx, LogLevelEmerge, structure, function, msg, id, vars)
+}
+func (l *Logger) LogAlertCtx(ctx context.Context, structure, function, msg string, id int, vars ...any) {
+	l.logCtx(ctx, LogLevelAlert, structure, function, msg, id, vars)
+}
+func (l *Logger) LogCriticalCtx(ctx context.Context, structure, function, msg string, id int, vars ...any) {
+	l.logCtx(ctx, LogLevelCritical, structure, function, msg, id, vars)
+}
+func (l *Logger) LogErrorCtx(ctx context.Context, structure, function, msg string, id int, vars ...any) {
+	l.logCtx(ctx, LogLevelError, structure, function, msg, id, vars)
+}
+func (l *Logger) LogWarningCtx(ctx context.Context, structure, function, msg string, id int, vars ...any) {
+	l.logCtx(ctx, LogLevelWarning, structure, function, msg, id, vars)
+}
+func (l *Logger) LogNoticeCtx(ctx context.Context, structure, function, msg string, id int, vars ...any) {
+	l.logCtx(ctx, LogLevelNotice, structure, function, msg, id, vars)
+}
+func (l *Logger) LogInfoCtx(ctx context.Context, structure, function, msg string, id int, vars ...any) {
+	l.logCtx(ctx, LogLevelInfo, structure, function, msg, id, vars)
+}
+func (l *Logger) LogDebugCtx(ctx context.Context, structure, function, msg string, id int, vars ...any) {
+	l.logCtx(ctx, LogLevelDebug, structure, function, msg, id, vars)
+}
+func (l *Logger) LogTraceCtx(ctx context.Context, structure, function, msg string, id int, vars ...any) {
+	l.logCtx(ctx, LogLevelTrace, structure, function, msg, id, vars)
+}
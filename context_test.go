@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type contextKey string
+
+func TestLogCtx(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, receiver, WithContextExtractor(ContextValueExtractor(contextKey("request_id"), "request_id")))
+	logger.AddContextExtractor(func(ctx context.Context) []Field {
+		if tenant, ok := ctx.Value(contextKey("tenant")).(string); ok {
+			return []Field{{Key: "tenant", Value: tenant}}
+		}
+		return nil
+	})
+
+	var ctx context.Context = context.WithValue(context.Background(), contextKey("request_id"), "r-1")
+	ctx = context.WithValue(ctx, contextKey("tenant"), "acme")
+
+	logger.With("k", "v").LogInfoCtx(ctx, "struct", "function", "my message", -1)
+	if !strings.HasSuffix(receiver.String(), "my message k=v request_id=r-1 tenant=acme\n") {
+		t.Errorf("the context fields are missing, got %q", receiver.String())
+	}
+
+	receiver.Reset()
+	logger.LogDebugCtx(ctx, "struct", "function", "my message", -1)
+	if receiver.String() != "" {
+		t.Errorf("the verbosity must apply to the Ctx methods, got %q", receiver.String())
+	}
+
+	receiver.Reset()
+	logger.LogInfoCtx(context.Background(), "struct", "function", "my message", -1)
+	if !strings.HasSuffix(receiver.String(), "my message\n") {
+		t.Errorf("no field expected without values in the context, got %q", receiver.String())
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, receiver)
+	var ctx context.Context = context.WithValue(context.Background(), contextKey("request_id"), "r-2")
+	var child *Logger = logger.WithContext(ctx).With("k", "v")
+
+	// An extractor registered after the derivation applies as well.
+	logger.AddContextExtractor(ContextValueExtractor(contextKey("request_id"), "request_id"))
+	child.LogWarning("struct", "function", "my message", -1)
+	if !strings.HasSuffix(receiver.String(), "my message k=v request_id=r-2\n") {
+		t.Errorf("the context fields are missing, got %q", receiver.String())
+	}
+	if child.Context() != ctx || logger.Context() != nil {
+		t.Error("the context must only be carried by the derived logger")
+	}
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const dedupDefaultWindow time.Duration = 30 * time.Second
+
+// DedupConfig describes the suppression of the repeated records.
+type DedupConfig struct {
+	// Window is the longest time the count of the repeated records is held
+	// before being logged, 30 seconds if not positive.
+	Window time.Duration
+}
+
+// WithDedup collapses the consecutive records having the same level,
+// structure, function, id, message and fields into a single one, followed by
+// a "last message repeated N times" record logged when a different record
+// comes, when config.Window elapses, or on Flush and Close.
+func WithDedup(config DedupConfig) Option {
+	return func(l *Logger) {
+		if config.Window <= 0 {
+			config.Window = dedupDefaultWindow
+		}
+		l.out.dedup = &deduplicator{out: l.out, window: config.Window}
+	}
+}
+
+type deduplicator struct {
+	mu       sync.Mutex
+	out      *output
+	window   time.Duration
+	last     Record
+	started  bool
+	repeated uint64
+	timer    *time.Timer
+}
+
+// write emits the record unless it repeats the previous one. The emission is
+// done under the lock so that the count precedes the next record.
+func (d *deduplicator) write(r *Record) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.started && !r.fatal && sameRecord(&d.last, r) {
+		d.repeated++
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.window, d.flush)
+		}
+		return
+	}
+	d.flushLocked()
+	d.last = Record{Level: r.Level, Structure: r.Structure, Function: r.Function, Id: r.Id, Message: r.Message, Fields: r.Fields}
+	d.started = true
+	d.out.emit(r)
+}
+
+// flush logs the count of the repeated records, if any.
+func (d *deduplicator) flush() {
+	d.mu.Lock()
+	d.flushLocked()
+	d.mu.Unlock()
+}
+
+func (d *deduplicator) flushLocked() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.repeated == 0 {
+		return
+	}
+	var r Record = Record{
+		Time:      time.Now(),
+		Level:     d.last.Level,
+		Structure: d.last.Structure,
+		Function:  d.last.Function,
+		Id:        -1,
+		Message:   "last message repeated " + strconv.FormatUint(d.repeated, 10) + " times",
+		Fields:    []Field{{Key: "repeated", Value: d.repeated}},
+	}
+	d.repeated = 0
+	d.out.emit(&r)
+}
+
+func sameRecord(a, b *Record) bool {
+	if a.Level != b.Level || a.Structure != b.Structure || a.Function != b.Function || a.Id != b.Id || a.Message != b.Message || len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Key != b.Fields[i].Key || !reflect.DeepEqual(a.Fields[i].Value, b.Fields[i].Value) {
+			return false
+		}
+	}
+	return true
+}
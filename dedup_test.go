@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithDedup(DedupConfig{}), WithExitFunc(func(int) {}))
+
+	for i := 0; i < 5; i++ {
+		logger.LogError("struct", "function", "connection refused", -1)
+	}
+	logger.With("k", 1).LogError("struct", "function", "connection refused", -1)
+	logger.With("k", 1).LogError("struct", "function", "connection refused", -1)
+	logger.LogError("struct", "function", "other", -1)
+	logger.LogError("struct", "function", "other", -1)
+	logger.FatalError("struct", "function", "other", -1)
+
+	var lines []string = strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	var expected []string = []string{
+		"connection refused",
+		"last message repeated 4 times repeated=4",
+		"connection refused k=1",
+		"last message repeated 1 times repeated=1",
+		"other",
+		"last message repeated 1 times repeated=1",
+		"other",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %q", len(expected), buffer.String())
+	}
+	for i := range expected {
+		if !strings.HasSuffix(lines[i], "] struct -> function: "+expected[i]) {
+			t.Errorf("line %d: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestDedupFlush(t *testing.T) {
+	var receiver *gatedWriter = newGatedWriter()
+	close(receiver.gate)
+	var logger *Logger = NewLogger(LogLevelTrace, receiver, WithDedup(DedupConfig{Window: 10 * time.Millisecond}))
+
+	logger.LogInfo("struct", "function", "message", -1)
+	logger.LogInfo("struct", "function", "message", -1)
+	var deadline time.Time = time.Now().Add(2 * time.Second)
+	for !strings.Contains(receiver.String(), "repeated 1 times") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.Contains(receiver.String(), "repeated 1 times") {
+		t.Errorf("the count must be logged after the window, got %q", receiver.String())
+	}
+
+	logger.LogInfo("struct", "function", "message", -1)
+	logger.LogInfo("struct", "function", "message", -1)
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(receiver.String(), "repeated 2 times") {
+		t.Errorf("Close must log the pending count, got %q", receiver.String())
+	}
+}
@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+const (
+	errFieldKey    string = "error"
+	errMaxDepth    int    = 32
+	errMaxMembers  int    = 32
+	errStackMethod string = "StackTrace"
+)
+
+// Err returns a Logger derived from l with err as the error field. The field
+// renders the chain of the wrapped errors, following both errors.Unwrap and
+// the members of errors.Join, with their type names and the stack traces they
+// carry through a Callers() []uintptr method or a StackTrace method returning
+// program counters, such as the one of github.com/pkg/errors. In text and
+// logfmt the chain reads:
+//
+//	read config: open x: file does not exist (*fmt.wrapError); cause: ...
+//
+// and JSON renders it as nested objects with the keys msg, type, stack, cause
+// and joined. A nil err returns l.
+func (l *Logger) Err(err error) *Logger {
+	if err == nil {
+		return l
+	}
+	return l.withFields([]Field{{Key: errFieldKey, Value: errorValue{err: err}}})
+}
+
+// errorValue is the value of the field added by Err.
+type errorValue struct {
+	err error
+}
+
+type errorNode struct {
+	Message string      `json:"msg"`
+	Type    string      `json:"type"`
+	Stack   []string    `json:"stack,omitempty"`
+	Cause   *errorNode  `json:"cause,omitempty"`
+	Joined  []errorNode `json:"joined,omitempty"`
+}
+
+func newErrorNode(err error, depth int) errorNode {
+	var node errorNode = errorNode{Message: err.Error(), Type: fmt.Sprintf("%T", err), Stack: errorStack(err)}
+	if depth >= errMaxDepth {
+		return node
+	}
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := wrapper.Unwrap(); cause != nil {
+			var child errorNode = newErrorNode(cause, depth+1)
+			node.Cause = &child
+		}
+	case interface{ Unwrap() []error }:
+		for i, member := range wrapper.Unwrap() {
+			if i == errMaxMembers {
+				break
+			}
+			if member != nil {
+				node.Joined = append(node.Joined, newErrorNode(member, depth+1))
+			}
+		}
+	}
+	return node
+}
+
+func (n errorNode) appendText(b []byte) []byte {
+	b = append(b, n.Message...)
+	b = append(b, " ("...)
+	b = append(b, n.Type...)
+	b = append(b, ')')
+	if len(n.Stack) > 0 {
+		b = append(b, "; stack: ["...)
+		b = append(b, strings.Join(n.Stack, ", ")...)
+		b = append(b, ']')
+	}
+	if len(n.Joined) > 0 {
+		b = append(b, "; joined: ["...)
+		for i, member := range n.Joined {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			b = member.appendText(b)
+		}
+		b = append(b, ']')
+	}
+	if n.Cause != nil {
+		b = append(b, "; cause: "...)
+		b = n.Cause.appendText(b)
+	}
+	return b
+}
+
+func (n errorNode) slogValue() slog.Value {
+	var attrs []slog.Attr = []slog.Attr{slog.String("msg", n.Message), slog.String("type", n.Type)}
+	if len(n.Stack) > 0 {
+		attrs = append(attrs, slog.Any("stack", n.Stack))
+	}
+	if n.Cause != nil {
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: n.Cause.slogValue()})
+	}
+	if len(n.Joined) > 0 {
+		var members []string = make([]string, len(n.Joined))
+		for i, member := range n.Joined {
+			members[i] = string(member.appendText(nil))
+		}
+		attrs = append(attrs, slog.Any("joined", members))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (v errorValue) String() string {
+	return errorNodeValue{node: newErrorNode(v.err, 0)}.String()
+}
+
+func (v errorValue) MarshalJSON() ([]byte, error) {
+	return errorNodeValue{node: newErrorNode(v.err, 0)}.MarshalJSON()
+}
+
+func (v errorValue) LogValue() slog.Value {
+	return errorNodeValue{node: newErrorNode(v.err, 0)}.LogValue()
+}
+
+// errorNodeValue is a field value rendering an error chain already walked,
+// such as a redacted one.
+type errorNodeValue struct {
+	node errorNode
+}
+
+func (v errorNodeValue) String() string {
+	return string(v.node.appendText(nil))
+}
+
+func (v errorNodeValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.node)
+}
+
+func (v errorNodeValue) LogValue() slog.Value {
+	return v.node.slogValue()
+}
+
+// errorStack returns the frames of the stack trace carried by err itself, not
+// by the errors it wraps.
+func errorStack(err error) []string {
+	if e, ok := err.(interface{ Callers() []uintptr }); ok {
+		return formatFrames(e.Callers())
+	}
+	return formatFrames(reflectStackTrace(err))
+}
+
+// reflectStackTrace returns the program counters returned by a StackTrace
+// method without argument returning a slice of integers, such as the
+// errors.StackTrace of github.com/pkg/errors whose frames are program
+// counters plus one, like the ones returned by runtime.Callers.
+func reflectStackTrace(err error) []uintptr {
+	var method reflect.Value = reflect.ValueOf(err).MethodByName(errStackMethod)
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+	var out reflect.Type = method.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	var frames reflect.Value = method.Call(nil)[0]
+	var pcs []uintptr = make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	return pcs
+}
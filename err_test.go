@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type stackTestError struct {
+	pcs []uintptr
+}
+
+func (e *stackTestError) Error() string {
+	return "boom"
+}
+
+func (e *stackTestError) Callers() []uintptr {
+	return e.pcs
+}
+
+type stackTraceTestFrame uintptr
+
+type stackTraceTestError struct {
+	pcs []uintptr
+}
+
+func (e *stackTraceTestError) Error() string {
+	return "boom"
+}
+
+func (e *stackTraceTestError) StackTrace() []stackTraceTestFrame {
+	var frames []stackTraceTestFrame = make([]stackTraceTestFrame, len(e.pcs))
+	for i, pc := range e.pcs {
+		frames[i] = stackTraceTestFrame(pc)
+	}
+	return frames
+}
+
+func newStackTestError() *stackTestError {
+	var pcs [8]uintptr
+	var n int = runtime.Callers(2, pcs[:])
+	return &stackTestError{pcs: pcs[:n]}
+}
+
+func TestErrText(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, buffer, WithFormatter(new(LogfmtFormatter)))
+	var err error = fmt.Errorf("read config: %w", &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist})
+
+	logger.Err(err).LogError("struct", "function", "failure", -1)
+	var expected string = `error="read config: open x: file does not exist (*fmt.wrapError); cause: open x: file does not exist (*fs.PathError); cause: file does not exist (*errors.errorString)"`
+	if !strings.HasSuffix(buffer.String(), " "+expected+"\n") {
+		t.Errorf("unexpected chain, got %q, expecting %q", buffer.String(), expected)
+	}
+
+	buffer.Reset()
+	logger.SetFormatter(new(TextFormatter))
+	logger.Err(errors.Join(errors.New("a"), fmt.Errorf("b: %w", errors.New("c")))).LogError("struct", "function", "failure", -1)
+	expected = `error="a\nb: c (*errors.joinError); joined: [a (*errors.errorString), b: c (*fmt.wrapError); cause: c (*errors.errorString)]"`
+	if !strings.HasSuffix(buffer.String(), " "+expected+"\n") {
+		t.Errorf("unexpected joined errors, got %q, expecting %q", buffer.String(), expected)
+	}
+
+	if logger.Err(nil) != logger {
+		t.Error("a nil error must not add a field")
+	}
+}
+
+func TestErrJSON(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, buffer, WithFormatter(new(JSONFormatter)))
+	var stackErr *stackTestError = newStackTestError()
+	var err error = fmt.Errorf("wrapped: %w", &stackTraceTestError{pcs: stackErr.pcs})
+
+	logger.Err(err).LogError("struct", "function", "failure", -1)
+	var record struct {
+		Error errorNode `json:"error"`
+	}
+	if e := json.Unmarshal(buffer.Bytes(), &record); e != nil {
+		t.Fatal(e)
+	}
+	if record.Error.Message != "wrapped: boom" || record.Error.Type != "*fmt.wrapError" || record.Error.Stack != nil {
+		t.Errorf("unexpected error %+v", record.Error)
+	}
+	var cause *errorNode = record.Error.Cause
+	if cause == nil || cause.Type != "*logger.stackTraceTestError" || len(cause.Stack) == 0 || !strings.HasPrefix(cause.Stack[0], "github.com/mmaFR/logger.TestErrJSON (") {
+		t.Errorf("the stack of the cause must be rendered, got %+v", cause)
+	}
+
+	if stack := errorStack(stackErr); len(stack) == 0 || !strings.HasPrefix(stack[0], "github.com/mmaFR/logger.TestErrJSON (") {
+		t.Errorf("the Callers method must be used, got %q", stack)
+	}
+}
+
+func TestErrSlog(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewSlogLogger(LogLevelInfo, slog.NewJSONHandler(buffer, nil))
+
+	logger.Err(fmt.Errorf("outer: %w", errors.New("inner"))).LogError("struct", "function", "failure", -1)
+	if !strings.Contains(buffer.String(), `"error":{"msg":"outer: inner","type":"*fmt.wrapError","cause":{"msg":"inner","type":"*errors.errorString"}}`) {
+		t.Errorf("the error must be a slog group, got %q", buffer.String())
+	}
+}
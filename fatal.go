@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+const defaultFatalTimeout time.Duration = 5 * time.Second
+
+// OnFatal registers a function run by the FatalXxx methods before the process
+// exits. The hooks run in the reverse order of their registration, like
+// deferred functions, and are shared with the derived loggers.
+func (l *Logger) OnFatal(hook func()) {
+	l.out.fatalMu.Lock()
+	l.out.fatalHooks = append(l.out.fatalHooks, hook)
+	l.out.fatalMu.Unlock()
+}
+
+// WithExitFunc replaces os.Exit as the function called by the FatalXxx
+// methods, mainly to test the fatal paths. The FatalXxx methods return when
+// the function returns.
+func WithExitFunc(exit func(code int)) Option {
+	return func(l *Logger) {
+		l.out.exit = exit
+	}
+}
+
+// WithExitCode sets the exit code used by the FatalXxx methods, 1 by default.
+func WithExitCode(code int) Option {
+	return func(l *Logger) {
+		l.out.exitCode = code
+	}
+}
+
+// WithFatalTimeout bounds the time spent by the FatalXxx methods to write the
+// queued records and flush the destinations, 5 seconds by default. The process
+// exits when it elapses, even if a destination is still flushing.
+func WithFatalTimeout(d time.Duration) Option {
+	return func(l *Logger) {
+		l.out.fatalTimeout = d
+	}
+}
+
+// fatal runs the hooks, writes the queued records, flushes the destination
+// and exits. A panicking hook does not prevent the others from running.
+func (o *output) fatal() {
+	o.fatalMu.Lock()
+	var hooks []func() = make([]func(), len(o.fatalHooks))
+	copy(hooks, o.fatalHooks)
+	o.fatalMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		runFatalHook(hooks[i])
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(context.Background(), o.fatalTimeout)
+	o.flush(ctx)
+	cancel()
+
+	o.exit(o.exitCode)
+}
+
+func runFatalHook(hook func()) {
+	defer func() {
+		recover()
+	}()
+	hook()
+}
+
+// flush waits for the queued records, then flushes the sinks buffering data.
+func (o *output) flush(ctx context.Context) error {
+	if o.dedup != nil {
+		o.dedup.flush()
+	}
+	if o.async != nil {
+		if err := o.async.flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	// A destination may take long to flush, such as an OTLPExporter retrying
+	// its requests, so the flush runs aside to honour ctx. It works on a copy of
+	// the sinks, which do their own locking, so that the records logged
+	// meanwhile are not blocked.
+	o.mu.Lock()
+	var sinks []*sink = make([]*sink, len(o.sinks))
+	copy(sinks, o.sinks)
+	o.mu.Unlock()
+	var done chan error = make(chan error, 1)
+	go func() {
+		var err error
+		for _, s := range sinks {
+			if sinkErr := s.flush(ctx); sinkErr != nil && err == nil {
+				err = sinkErr
+			}
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type flushRecorder struct {
+	bytes.Buffer
+	flushed int
+}
+
+func (w *flushRecorder) Flush() error {
+	w.flushed++
+	return nil
+}
+
+func TestFatal(t *testing.T) {
+	var receiver *flushRecorder = new(flushRecorder)
+	var exitCode int = -1
+	var order []string
+	var logger *Logger = NewLogger(LogLevelNull, receiver, WithExitFunc(func(code int) { exitCode = code }), WithExitCode(3))
+
+	logger.OnFatal(func() { order = append(order, "first") })
+	logger.OnFatal(func() { panic("broken hook") })
+	logger.With("k", "v").OnFatal(func() { order = append(order, "last") })
+
+	logger.FatalError("struct", "function", "my message", -1)
+	if exitCode != 3 {
+		t.Errorf("exit code 3 expected, got %d", exitCode)
+	}
+	if strings.Join(order, ",") != "last,first" {
+		t.Errorf("the hooks must run in reverse order despite a panic, got %v", order)
+	}
+	if receiver.flushed != 1 {
+		t.Errorf("the destination must be flushed once, got %d", receiver.flushed)
+	}
+	if !strings.HasSuffix(receiver.String(), "[ERROR   ] struct -> function: my message\n") {
+		t.Errorf("the fatal record must be written whatever the verbosity, got %q", receiver.String())
+	}
+}
+
+func TestFatalAsync(t *testing.T) {
+	var receiver *gatedWriter = newGatedWriter()
+	var exited bool
+	var logger *Logger = NewLogger(LogLevelInfo, receiver, WithAsync(AsyncConfig{}), WithExitFunc(func(int) { exited = true }))
+
+	logger.OnFatal(func() { close(receiver.gate) })
+	logger.LogInfo("struct", "function", "queued", -1)
+	logger.FatalCritical("struct", "function", "fatal", -1)
+	if !exited {
+		t.Fatal("the exit function was not called")
+	}
+	if !strings.Contains(receiver.String(), "queued\n") || !strings.HasSuffix(receiver.String(), "fatal\n") {
+		t.Errorf("the queued records must be written before exiting, got %q", receiver.String())
+	}
+}
+
+func TestFatalTimeout(t *testing.T) {
+	var receiver *gatedWriter = newGatedWriter()
+	defer close(receiver.gate)
+	var exited bool
+	var logger *Logger = NewLogger(LogLevelInfo, receiver, WithAsync(AsyncConfig{}), WithExitFunc(func(int) { exited = true }), WithFatalTimeout(20*time.Millisecond))
+
+	var start time.Time = time.Now()
+	logger.FatalAlert("struct", "function", "stuck", -1)
+	if !exited || time.Since(start) > time.Second {
+		t.Error("the fatal path must exit after the timeout when the destination is stuck")
+	}
+}
+
+func TestFatalAsyncFullQueue(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowDropBelowLevel} {
+		var receiver *gatedWriter = newGatedWriter()
+		var exitCode int = -1
+		var logger *Logger = NewLogger(LogLevelDebug, receiver, WithAsync(AsyncConfig{QueueSize: 1, Policy: policy, DropLevel: LogLevelEmerge}), WithExitFunc(func(code int) { exitCode = code }))
+
+		// The first record blocks the background goroutine on the gate, the
+		// second one fills the queue.
+		logger.LogInfo("struct", "function", "first", -1)
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			logger.out.async.mu.Lock()
+			var busy bool = logger.out.async.busy
+			logger.out.async.mu.Unlock()
+			if busy {
+				break
+			}
+		}
+		logger.LogInfo("struct", "function", "second", -1)
+		logger.OnFatal(func() { close(receiver.gate) })
+		logger.FatalError("struct", "function", "fatal", -1)
+
+		if exitCode != 1 {
+			t.Errorf("policy %d: exit code 1 expected, got %d", policy, exitCode)
+		}
+		if logger.Dropped() != 0 {
+			t.Errorf("policy %d: the fatal record must not be dropped, got %d dropped", policy, logger.Dropped())
+		}
+		if !strings.Contains(receiver.String(), "second\n") || !strings.HasSuffix(receiver.String(), "fatal\n") {
+			t.Errorf("policy %d: the fatal record must be written after the queued ones, got %q", policy, receiver.String())
+		}
+	}
+}
+
+// stuckFlusher never completes its flush until released.
+type stuckFlusher struct {
+	bytes.Buffer
+	release chan struct{}
+}
+
+func (w *stuckFlusher) Flush() error {
+	<-w.release
+	return nil
+}
+
+func TestFatalTimeoutFlush(t *testing.T) {
+	var receiver *stuckFlusher = &stuckFlusher{release: make(chan struct{})}
+	defer close(receiver.release)
+	var exited bool
+	var logger *Logger = NewLogger(LogLevelInfo, receiver, WithExitFunc(func(int) { exited = true }), WithFatalTimeout(20*time.Millisecond))
+
+	var start time.Time = time.Now()
+	logger.FatalAlert("struct", "function", "stuck", -1)
+	if !exited || time.Since(start) > time.Second {
+		t.Error("the fatal path must exit after the timeout when the flush of a destination is stuck")
+	}
+	if !strings.HasSuffix(receiver.String(), "stuck\n") {
+		t.Errorf("the fatal record must be written, got %q", receiver.String())
+	}
+}
+
+func TestFlushTimeoutDoesNotBlock(t *testing.T) {
+	var receiver *stuckFlusher = &stuckFlusher{release: make(chan struct{})}
+	defer close(receiver.release)
+	var logger *Logger = NewLogger(LogLevelInfo, receiver)
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := logger.Flush(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("the flush must time out, got %v", err)
+	}
+
+	var done chan struct{} = make(chan struct{})
+	go func() {
+		logger.LogInfo("struct", "function", "after", -1)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the records must not wait for a timed out flush")
+	}
+}
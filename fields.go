@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const fieldMissingValue string = "!MISSING"
+
+// Field is a key/value pair attached to every line emitted by a Logger.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Fields is a convenience map used by WithFields.
+type Fields map[string]any
+
+// With returns a derived Logger carrying the given key/value pairs in addition
+// to the fields of l. Keys that are not strings are converted with fmt.Sprint,
+// a trailing key without value gets the value "!MISSING".
+func (l *Logger) With(keyvals ...any) *Logger {
+	var fields []Field = make([]Field, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		var key string
+		var ok bool
+		if key, ok = keyvals[i].(string); !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+		if i+1 < len(keyvals) {
+			fields = append(fields, Field{Key: key, Value: keyvals[i+1]})
+		} else {
+			fields = append(fields, Field{Key: key, Value: fieldMissingValue})
+		}
+	}
+	return l.withFields(fields)
+}
+
+// WithFields returns a derived Logger carrying the given fields in addition to
+// the fields of l. The fields are sorted by key to keep the output stable.
+func (l *Logger) WithFields(fields Fields) *Logger {
+	var keys []string = make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var list []Field = make([]Field, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, Field{Key: key, Value: fields[key]})
+	}
+	return l.withFields(list)
+}
+
+// Fields returns a copy of the fields carried by l.
+func (l *Logger) Fields() []Field {
+	var fields []Field = make([]Field, len(l.fields))
+	copy(fields, l.fields)
+	return fields
+}
+
+func (l *Logger) withFields(fields []Field) *Logger {
+	var child *Logger = l.derive()
+	child.fields = make([]Field, 0, len(l.fields)+len(fields))
+	child.fields = append(child.fields, l.fields...)
+	child.fields = append(child.fields, fields...)
+	return child
+}
+
+// derive returns a copy of l sharing its output, and so its verbosity.
+func (l *Logger) derive() *Logger {
+	var child *Logger = new(Logger)
+	child.out = l.out
+	child.defaultStructure = l.defaultStructure
+	child.defaultFunction = l.defaultFunction
+	child.fields = l.fields
+	child.ctx = l.ctx
+	return child
+}
+
+func appendFieldsText(b []byte, fields []Field) []byte {
+	for _, f := range fields {
+		b = append(b, ' ')
+		b = appendTextValue(b, f.Key)
+		b = append(b, '=')
+		b = appendTextValue(b, fieldString(f.Value))
+	}
+	return b
+}
+
+func fieldString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+func appendTextValue(b []byte, s string) []byte {
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") || !strconv.CanBackquote(s) {
+		return strconv.AppendQuote(b, s)
+	}
+	return append(b, s...)
+}
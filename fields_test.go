@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, receiver)
+	var child *Logger = logger.With("user_id", 42, "request_id", "abc def")
+
+	child.LogInfo("struct", "function", "my message", -1)
+	var expected string = fmt.Sprintf(logPattern, logLevelInfoPrefix, "struct", "function", `my message user_id=42 request_id="abc def"`)
+	if !strings.HasSuffix(receiver.String(), expected) {
+		t.Errorf("incorrect string returned with fields, got %q, expecting suffix %q", receiver.String(), expected)
+	}
+
+	receiver.Reset()
+	logger.LogInfo("struct", "function", "my message", -1)
+	expected = fmt.Sprintf(logPattern, logLevelInfoPrefix, "struct", "function", "my message")
+	if !strings.HasSuffix(receiver.String(), expected) {
+		t.Errorf("the parent logger must not carry the fields of its child, got %q", receiver.String())
+	}
+
+	receiver.Reset()
+	child.LogDebug("struct", "function", "my message", -1)
+	if receiver.String() != "" {
+		t.Errorf("the child logger must inherit the verbosity of its parent, got %q", receiver.String())
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, receiver).With("a", 1).WithFields(Fields{"c": 3, "b": 2})
+
+	logger.LogInfo("struct", "function", "my message", 7)
+	var expected string = fmt.Sprintf(logPatternWithId, logLevelInfoPrefix, "struct", "function", 7, "my message a=1 b=2 c=3")
+	if !strings.HasSuffix(receiver.String(), expected) {
+		t.Errorf("incorrect string returned with fields, got %q, expecting suffix %q", receiver.String(), expected)
+	}
+	if len(logger.Fields()) != 3 {
+		t.Errorf("3 fields expected, got %d", len(logger.Fields()))
+	}
+}
+
+func TestWithMissingValue(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, receiver).With("key")
+
+	logger.LogInfo("struct", "function", "my message", -1)
+	if !strings.HasSuffix(receiver.String(), "my message key="+fieldMissingValue+"\n") {
+		t.Errorf("missing value not rendered, got %q", receiver.String())
+	}
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+const textTimeLayout string = "2006/01/02 15:04:05.000000 "
+
+var levelPrefixMap map[LogLevel]string = map[LogLevel]string{
+	LogLevelEmerge:   logLevelEmergePrefix,
+	LogLevelAlert:    logLevelAlertPrefix,
+	LogLevelCritical: logLevelCriticalPrefix,
+	LogLevelError:    logLevelErrorPrefix,
+	LogLevelWarning:  logLevelWarningPrefix,
+	LogLevelNotice:   logLevelNoticePrefix,
+	LogLevelInfo:     logLevelInfoPrefix,
+	LogLevelDebug:    logLevelDebugPrefix,
+	LogLevelTrace:    logLevelTracePrefix,
+}
+
+// Record is a single log event as handed to a Formatter. Message is already
+// formatted with its vars, Id is negative when no id was given and Context is
+// nil unless the record was logged with a context.
+type Record struct {
+	Time      time.Time
+	Level     LogLevel
+	Structure string
+	Function  string
+	Id        int
+	Message   string
+	Fields    []Field
+	Context   context.Context
+	// Caller is set by the loggers built with WithCaller.
+	Caller Caller
+	// Stack is set by the loggers built with WithStackTrace, it holds the
+	// frames of the goroutine, or the lines of the dump of all the goroutines.
+	Stack []string
+
+	// fatal is set for the records of the FatalXxx methods, written to every
+	// sink whatever its level.
+	fatal bool
+}
+
+// Formatter renders a Record into the bytes written to the destination of a
+// Logger. The returned slice must end with a new line and must not be retained
+// by the Formatter.
+type Formatter interface {
+	Format(r *Record) []byte
+}
+
+// TextFormatter is the default Formatter. It renders the records with the
+// bracketed layout described by logPattern and logPatternWithId, preceded by
+// the date and time with microseconds and followed by the fields as key=value.
+// The stack trace, if any, follows on lines indented by a tab.
+type TextFormatter struct {
+	// DisableTime omits the date and time, for destinations adding their own.
+	DisableTime bool
+	// DisableLevel omits the level prefix, for destinations carrying the level
+	// on their own.
+	DisableLevel bool
+	// TimeLayout replaces the default layout of the date and time.
+	TimeLayout string
+}
+
+func (f *TextFormatter) Format(r *Record) []byte {
+	var b []byte = make([]byte, 0, 64+len(r.Structure)+len(r.Function)+len(r.Message))
+	if !f.DisableTime && f.TimeLayout != "" {
+		b = r.Time.AppendFormat(b, f.TimeLayout)
+		b = append(b, ' ')
+	} else if !f.DisableTime {
+		b = r.Time.AppendFormat(b, textTimeLayout)
+	}
+	if !f.DisableLevel {
+		b = append(b, levelPrefixMap[r.Level]...)
+		b = append(b, ' ')
+	}
+	b = append(b, r.Structure...)
+	b = append(b, " -> "...)
+	b = append(b, r.Function...)
+	if r.Id >= 0 {
+		b = append(b, '-')
+		b = strconv.AppendInt(b, int64(r.Id), 10)
+	}
+	b = append(b, ": "...)
+	b = append(b, r.Message...)
+	if !r.Caller.IsZero() {
+		b = append(b, " caller="...)
+		b = append(b, r.Caller.String()...)
+	}
+	b = appendFieldsText(b, r.Fields)
+	b = appendStackText(b, r.Stack)
+	return append(b, '\n')
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+)
+
+type testFormatter struct{}
+
+func (f testFormatter) Format(r *Record) []byte {
+	return []byte(fmt.Sprintf("%s|%s|%s|%d|%s|%d\n", GetLevelName(r.Level), r.Structure, r.Function, r.Id, r.Message, len(r.Fields)))
+}
+
+func TestTextFormatterMatchesLogPattern(t *testing.T) {
+	var date time.Time = time.Date(2023, 5, 17, 8, 4, 2, 123456789, time.Local)
+	var expected *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var reference *log.Logger = log.New(expected, "", log.Ldate|log.Lmicroseconds)
+	var formatter *TextFormatter = new(TextFormatter)
+
+	for lvl := LogLevelEmerge; lvl <= LogLevelTrace; lvl++ {
+		for _, id := range []int{-1, 0, 12} {
+			var r Record = Record{Time: date, Level: lvl, Structure: "struct", Function: "function", Id: id, Message: "my message"}
+			var got []byte = formatter.Format(&r)
+			expected.Reset()
+			if id < 0 {
+				reference.Printf(logPattern, levelPrefixMap[lvl], "struct", "function", "my message")
+			} else {
+				reference.Printf(logPatternWithId, levelPrefixMap[lvl], "struct", "function", id, "my message")
+			}
+			// The reference logger uses the current time, only the layout after the date is compared.
+			if string(got[len(textTimeLayout):]) != expected.String()[len(textTimeLayout):] {
+				t.Errorf("incorrect text for the log level %s and id %d, got %q, expecting %q", GetLevelName(lvl), id, got, expected.String())
+			}
+		}
+	}
+
+	var r Record = Record{Time: date, Level: LogLevelInfo, Structure: "s", Function: "f", Id: -1, Message: "m"}
+	if got := string(formatter.Format(&r)); got != "2023/05/17 08:04:02.123456 [INFO    ] s -> f: m\n" {
+		t.Errorf("incorrect timestamp, got %q", got)
+	}
+}
+
+func TestWithFormatter(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, receiver, WithFormatter(testFormatter{}))
+
+	logger.With("k", "v").LogWarning("struct", "function", "my message %d", 3, 1)
+	if receiver.String() != "WARNING|struct|function|3|my message 1|1\n" {
+		t.Errorf("the custom formatter was not used, got %q", receiver.String())
+	}
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+)
+
+const levelHandlerMaxBody int64 = 1024
+
+type levelBody struct {
+	Level *LogLevel `json:"level"`
+}
+
+type levelResponse struct {
+	Level LogLevel `json:"level"`
+	Value uint8    `json:"value"`
+}
+
+// LevelHandler returns an http.Handler exposing the verbosity of l:
+//
+//   - GET replies with the current level as {"level":"INFO","value":7}, a
+//     verbosity above TRACE being named TRACE.
+//   - PUT changes it, the level is read from the level query parameter, a
+//     {"level":...} JSON body or a plain text body, as accepted by
+//     ParseLogLevel.
+//
+// The handler is not protected in any way, it should be mounted on an
+// administration listener.
+func LevelHandler(l *Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var level LogLevel
+			var err error
+			if level, err = levelFromRequest(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			l.SetVerbosity(level)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		var level LogLevel = l.Verbosity()
+		w.Header().Set("Content-Type", "application/json")
+		// A verbosity without a name has the effect of TRACE.
+		json.NewEncoder(w).Encode(levelResponse{Level: NewLogLevel(uint8(level)), Value: uint8(level)})
+	})
+}
+
+func levelFromRequest(r *http.Request) (LogLevel, error) {
+	if r.URL.Query().Has("level") {
+		return ParseLogLevel(r.URL.Query().Get("level"))
+	}
+
+	var body []byte
+	var err error
+	if body, err = io.ReadAll(io.LimitReader(r.Body, levelHandlerMaxBody)); err != nil {
+		return 0, err
+	}
+	var mediaType string
+	mediaType, _, _ = mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return ParseLogLevel(string(body))
+	}
+
+	var v levelBody
+	if err = json.Unmarshal(body, &v); err != nil {
+		return 0, errors.New("logger: invalid JSON body: " + err.Error())
+	}
+	if v.Level == nil {
+		return 0, errors.New("logger: missing level")
+	}
+	return *v.Level, nil
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, buffer)
+	var child *Logger = logger.With("k", "v")
+	var server *httptest.Server = httptest.NewServer(LevelHandler(logger))
+	defer server.Close()
+
+	var tests = []struct {
+		url         string
+		contentType string
+		body        string
+		status      int
+		level       LogLevel
+	}{
+		{url: "", body: "trace", status: http.StatusOK, level: LogLevelTrace},
+		{url: "", contentType: "application/json", body: `{"level":"Warning"}`, status: http.StatusOK, level: LogLevelWarning},
+		{url: "", contentType: "application/json", body: `{"level":4}`, status: http.StatusOK, level: LogLevelError},
+		{url: "?level=none", status: http.StatusOK, level: LogLevelNull},
+		{url: "?level=200", status: http.StatusOK, level: LogLevelTrace},
+		{url: "", body: "DEBUG\n", status: http.StatusOK, level: LogLevelDebug},
+		{url: "", body: "verbose", status: http.StatusBadRequest, level: LogLevelDebug},
+		{url: "", contentType: "application/json", body: `{"lvl":"INFO"}`, status: http.StatusBadRequest, level: LogLevelDebug},
+		{url: "?level=", status: http.StatusBadRequest, level: LogLevelDebug},
+	}
+	for _, test := range tests {
+		var req *http.Request
+		var err error
+		if req, err = http.NewRequest(http.MethodPut, server.URL+test.url, strings.NewReader(test.body)); err != nil {
+			t.Fatal(err)
+		}
+		if test.contentType != "" {
+			req.Header.Set("Content-Type", test.contentType)
+		}
+		var resp *http.Response
+		if resp, err = http.DefaultClient.Do(req); err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("PUT %q %q: expected the status %d, got %d", test.url, test.body, test.status, resp.StatusCode)
+		}
+		if logger.Verbosity() != test.level || child.Verbosity() != test.level {
+			t.Errorf("PUT %q %q: expected the level %s, got %s and %s", test.url, test.body, GetLevelName(test.level), GetLevelName(logger.Verbosity()), GetLevelName(child.Verbosity()))
+		}
+	}
+
+	var resp *http.Response
+	var err error
+	if resp, err = http.Get(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	var body []byte
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "{\"level\":\"DEBUG\",\"value\":8}\n" {
+		t.Errorf("unexpected GET response %q", body)
+	}
+
+	if resp, err = http.Post(server.URL, "text/plain", strings.NewReader("INFO")); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") == "" {
+		t.Errorf("POST must be refused, got the status %d", resp.StatusCode)
+	}
+
+	child.LogDebug("struct", "function", "debug", -1)
+	child.LogTrace("struct", "function", "trace", -1)
+	if !strings.Contains(buffer.String(), "debug") || strings.Contains(buffer.String(), "trace") {
+		t.Errorf("the derived loggers must follow the verbosity, got %q", buffer.String())
+	}
+
+	logger.SetVerbosity(200)
+	if resp, err = http.Get(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "{\"level\":\"TRACE\",\"value\":200}\n" {
+		t.Errorf("a verbosity without a name must be reported as TRACE, got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestSetVerbosityConcurrent(t *testing.T) {
+	var logger *Logger = NewLogger(LogLevelInfo, io.Discard)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				logger.LogDebug("struct", "function", "message %d", j, j)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				logger.SetVerbosity(LogLevel(uint8(i+j) % uint8(LogLevelTrace+1)))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
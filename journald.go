@@ -0,0 +1,242 @@
+package logger
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const journaldDefaultSocket string = "/run/systemd/journal/socket"
+
+// JournaldConfig describes how to reach the journal.
+type JournaldConfig struct {
+	// SocketPath is the native datagram socket of journald,
+	// /run/systemd/journal/socket if empty.
+	SocketPath string
+	// Identifier is sent as SYSLOG_IDENTIFIER, it defaults to the default
+	// structure of the Logger, then to the name of the executable.
+	Identifier string
+}
+
+// JournaldWriter sends each record to systemd-journald with its native
+// protocol. The formatted record is sent as MESSAGE, the level as PRIORITY,
+// the function as CODE_FUNC, the structure as STRUCTURE, the caller as
+// CODE_FILE and CODE_LINE, the stack as STACK_TRACE, the id as LOG_ID and
+// MESSAGE_ID, and the fields with their keys converted to journal field names.
+type JournaldWriter struct {
+	mu               sync.Mutex
+	config           JournaldConfig
+	conn             *net.UnixConn
+	addr             *net.UnixAddr
+	defaultStructure string
+}
+
+func NewJournaldWriter(config JournaldConfig) (*JournaldWriter, error) {
+	var w *JournaldWriter = &JournaldWriter{config: config}
+	if w.config.SocketPath == "" {
+		w.config.SocketPath = journaldDefaultSocket
+	}
+	w.addr = &net.UnixAddr{Name: w.config.SocketPath, Net: "unixgram"}
+
+	var err error
+	if w.conn, err = net.DialUnix("unixgram", nil, w.addr); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// NewJournaldLogger returns a Logger writing to a new JournaldWriter, closed by
+// Logger.Close. The date and the level are left out of the messages as the
+// journal carries them.
+func NewJournaldLogger(level LogLevel, config JournaldConfig, opts ...Option) (*Logger, error) {
+	var w *JournaldWriter
+	var err error
+	if w, err = NewJournaldWriter(config); err != nil {
+		return nil, err
+	}
+	opts = append([]Option{WithFormatter(&TextFormatter{DisableTime: true, DisableLevel: true})}, opts...)
+	var l *Logger = NewLogger(level, w, opts...)
+	l.out.closers = append(l.out.closers, w)
+	return l, nil
+}
+
+func (w *JournaldWriter) setDefaultStructure(s string) {
+	w.mu.Lock()
+	w.defaultStructure = s
+	w.mu.Unlock()
+}
+
+func (w *JournaldWriter) identifier() string {
+	switch {
+	case w.config.Identifier != "":
+		return w.config.Identifier
+	case w.defaultStructure != "":
+		return w.defaultStructure
+	default:
+		return filepath.Base(os.Args[0])
+	}
+}
+
+// Write sends p as a message with the priority of LogLevelInfo.
+func (w *JournaldWriter) Write(p []byte) (int, error) {
+	var r Record = Record{Time: time.Now(), Level: LogLevelInfo, Id: -1}
+	return w.WriteRecord(&r, p)
+}
+
+func (w *JournaldWriter) WriteRecord(r *Record, p []byte) (int, error) {
+	for len(p) > 0 && p[len(p)-1] == '\n' {
+		p = p[:len(p)-1]
+	}
+	var severity uint8
+	var exists bool
+	if severity, exists = syslogSeverityMap[r.Level]; !exists {
+		severity = syslogSeverityMap[LogLevelInfo]
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var b []byte = make([]byte, 0, 128+len(p))
+	b = appendJournaldField(b, "MESSAGE", string(p))
+	b = appendJournaldField(b, "PRIORITY", strconv.Itoa(int(severity)))
+	b = appendJournaldField(b, "SYSLOG_IDENTIFIER", w.identifier())
+	if r.Function != "" {
+		b = appendJournaldField(b, "CODE_FUNC", r.Function)
+	}
+	if r.Structure != "" {
+		b = appendJournaldField(b, "STRUCTURE", r.Structure)
+	}
+	if !r.Caller.IsZero() {
+		b = appendJournaldField(b, "CODE_FILE", r.Caller.File)
+		b = appendJournaldField(b, "CODE_LINE", strconv.Itoa(r.Caller.Line))
+	}
+	if len(r.Stack) > 0 {
+		b = appendJournaldField(b, "STACK_TRACE", strings.Join(r.Stack, "\n"))
+	}
+	if r.Id >= 0 {
+		b = appendJournaldField(b, "LOG_ID", strconv.Itoa(r.Id))
+		b = appendJournaldField(b, "MESSAGE_ID", journaldMessageId(r.Id))
+	}
+	for _, field := range r.Fields {
+		b = appendJournaldField(b, journaldFieldName(field.Key), fieldString(field.Value))
+	}
+
+	var err error
+	if _, err = w.conn.Write(b); err == nil {
+		return len(p), nil
+	}
+	// The journal may have been restarted, one reconnection is attempted.
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENOTCONN) {
+		var conn *net.UnixConn
+		if conn, err = net.DialUnix("unixgram", nil, w.addr); err != nil {
+			return 0, err
+		}
+		w.conn.Close()
+		w.conn = conn
+		if _, err = w.conn.Write(b); err == nil {
+			return len(p), nil
+		}
+	}
+	// Entries larger than the maximum datagram size are passed through a file
+	// descriptor, as documented by the journal native protocol.
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		if err = journaldSendFd(w.conn, b); err == nil {
+			return len(p), nil
+		}
+	}
+	return 0, err
+}
+
+func (w *JournaldWriter) Close() error {
+	return w.conn.Close()
+}
+
+// appendJournaldField uses the simple KEY=value form when possible and the
+// binary form, with the length as a little endian uint64, for values holding a
+// new line.
+func appendJournaldField(b []byte, key, value string) []byte {
+	b = append(b, key...)
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\n' {
+			b = append(b, '\n')
+			b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+			b = append(b, value...)
+			return append(b, '\n')
+		}
+	}
+	b = append(b, '=')
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+// journaldReservedFields are the fields set by the JournaldWriter itself and
+// the well-known fields of the journal, prefixed like the invalid names when
+// used as the key of a field.
+var journaldReservedFields map[string]struct{} = map[string]struct{}{
+	"MESSAGE":            {},
+	"MESSAGE_ID":         {},
+	"PRIORITY":           {},
+	"CODE_FILE":          {},
+	"CODE_LINE":          {},
+	"CODE_FUNC":          {},
+	"ERRNO":              {},
+	"INVOCATION_ID":      {},
+	"USER_INVOCATION_ID": {},
+	"SYSLOG_FACILITY":    {},
+	"SYSLOG_IDENTIFIER":  {},
+	"SYSLOG_PID":         {},
+	"SYSLOG_TIMESTAMP":   {},
+	"SYSLOG_RAW":         {},
+	"DOCUMENTATION":      {},
+	"TID":                {},
+	"UNIT":               {},
+	"USER_UNIT":          {},
+	"STRUCTURE":          {},
+	"STACK_TRACE":        {},
+	"LOG_ID":             {},
+}
+
+// journaldFieldName converts key to a valid journal field name: upper case
+// letters, digits and underscores, not starting by an underscore, which is
+// reserved to the trusted fields, or by a digit. The names of the reserved
+// fields are prefixed by F_ so that a field never overrides them.
+func journaldFieldName(key string) string {
+	var b []byte = make([]byte, 0, len(key)+2)
+	for i := 0; i < len(key) && len(b) < 64; i++ {
+		var c byte = key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			b = append(b, c-'a'+'A')
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			b = append(b, c)
+		case len(b) > 0:
+			b = append(b, '_')
+		}
+	}
+	var reserved bool
+	_, reserved = journaldReservedFields[string(b)]
+	if len(b) == 0 || (b[0] >= '0' && b[0] <= '9') || reserved {
+		b = append([]byte{'F', '_'}, b...)
+	}
+	return string(b[:min(len(b), 64)])
+}
+
+// journaldMessageId renders id as the 128 bits hexadecimal identifier expected
+// in MESSAGE_ID.
+func journaldMessageId(id int) string {
+	var s string = strconv.FormatUint(uint64(id), 16)
+	var b []byte = make([]byte, 32-len(s), 32)
+	for i := range b {
+		b[i] = '0'
+	}
+	return string(append(b, s...))
+}
+
+var _ RecordWriter = (*JournaldWriter)(nil)
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package logger
+
+import (
+	"errors"
+	"net"
+)
+
+func journaldSendFd(conn *net.UnixConn, b []byte) error {
+	return errors.New("logger: entry too large for the journal socket")
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readJournaldEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	var buffer []byte = make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var n int
+	var err error
+	if n, _, err = conn.ReadFromUnix(buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	var entry map[string]string = make(map[string]string)
+	var data []byte = buffer[:n]
+	for len(data) > 0 {
+		var eol int = bytes.IndexByte(data, '\n')
+		var line []byte = data[:eol]
+		if eq := bytes.IndexByte(line, '='); eq >= 0 {
+			entry[string(line[:eq])] = string(line[eq+1:])
+			data = data[eol+1:]
+			continue
+		}
+		var size uint64 = binary.LittleEndian.Uint64(data[eol+1 : eol+9])
+		entry[string(line)] = string(data[eol+9 : eol+9+int(size)])
+		data = data[eol+9+int(size)+1:]
+	}
+	return entry
+}
+
+func TestJournald(t *testing.T) {
+	var path string = filepath.Join(t.TempDir(), "journal.sock")
+	var conn *net.UnixConn
+	var err error
+	if conn, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"}); err != nil {
+		t.Skipf("unable to listen on a unix socket: %s", err)
+	}
+	defer conn.Close()
+
+	var logger *Logger
+	if logger, err = NewJournaldLogger(LogLevelInfo, JournaldConfig{SocketPath: path}); err != nil {
+		t.Fatal(err)
+	}
+	logger.SetDefaultStructure("myapp")
+
+	logger.With("user id", 42, "_trusted", "no", "multi", "a\nb").LogError("db.Pool", "Acquire", "timeout after %ds", 26, 5)
+	var entry map[string]string = readJournaldEntry(t, conn)
+	var expected map[string]string = map[string]string{
+		"MESSAGE":           `db.Pool -> Acquire-26: timeout after 5s "user id"=42 _trusted=no multi="a\nb"`,
+		"PRIORITY":          "3",
+		"SYSLOG_IDENTIFIER": "myapp",
+		"CODE_FUNC":         "Acquire",
+		"STRUCTURE":         "db.Pool",
+		"LOG_ID":            "26",
+		"MESSAGE_ID":        "0000000000000000000000000000001a",
+		"USER_ID":           "42",
+		"TRUSTED":           "no",
+		"MULTI":             "a\nb",
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("incorrect value for the field %s, got %q, expecting %q", key, entry[key], value)
+		}
+	}
+
+	logger.LogInfo("struct", "function", "no id", -1)
+	entry = readJournaldEntry(t, conn)
+	if entry["PRIORITY"] != "6" {
+		t.Errorf("incorrect priority, got %q", entry["PRIORITY"])
+	}
+	if _, ok := entry["LOG_ID"]; ok {
+		t.Error("LOG_ID must be omitted when the id is negative")
+	}
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	var tests map[string]string = map[string]string{
+		"request_id":                  "REQUEST_ID",
+		"__x":                         "X",
+		"1st":                         "F_1ST",
+		"a.b-c":                       "A_B_C",
+		"":                            "F_",
+		"message":                     "F_MESSAGE",
+		"priority":                    "F_PRIORITY",
+		"code_func":                   "F_CODE_FUNC",
+		"Syslog-Identifier":           "F_SYSLOG_IDENTIFIER",
+		"message_text":                "MESSAGE_TEXT",
+		strings.Repeat("x", 70):       strings.Repeat("X", 64),
+		"9" + strings.Repeat("x", 70): "F_9" + strings.Repeat("X", 61),
+	}
+	for in, expected := range tests {
+		if got := journaldFieldName(in); got != expected {
+			t.Errorf("incorrect field name for %q, got %q, expecting %q", in, got, expected)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const hexDigits string = "0123456789abcdef"
+
+var jsonReservedKeys map[string]bool = map[string]bool{
+	"time":      true,
+	"level":     true,
+	"structure": true,
+	"function":  true,
+	"id":        true,
+	"msg":       true,
+	"caller":    true,
+	"stack":     true,
+}
+
+// JSONFormatter renders each record as a single line JSON object (NDJSON) with
+// the keys time, level, structure, function, id, msg, caller, stack followed
+// by the fields. The id is omitted when negative, the caller and the stack, an
+// array of strings, when not captured. A
+// field whose key collides with one of those keys is prefixed by "fields.".
+type JSONFormatter struct {
+	// TimeLayout is the layout used for the time key, time.RFC3339Nano if empty.
+	TimeLayout string
+}
+
+func (f *JSONFormatter) Format(r *Record) []byte {
+	var layout string = f.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+
+	var b []byte = make([]byte, 0, 128+len(r.Structure)+len(r.Function)+len(r.Message))
+	b = append(b, `{"time":"`...)
+	b = r.Time.AppendFormat(b, layout)
+	b = append(b, `","level":`...)
+	b = appendJSONString(b, GetLevelName(r.Level))
+	b = append(b, `,"structure":`...)
+	b = appendJSONString(b, r.Structure)
+	b = append(b, `,"function":`...)
+	b = appendJSONString(b, r.Function)
+	if r.Id >= 0 {
+		b = append(b, `,"id":`...)
+		b = strconv.AppendInt(b, int64(r.Id), 10)
+	}
+	b = append(b, `,"msg":`...)
+	b = appendJSONString(b, r.Message)
+	if !r.Caller.IsZero() {
+		b = append(b, `,"caller":`...)
+		b = appendJSONString(b, r.Caller.String())
+	}
+	if len(r.Stack) > 0 {
+		b = append(b, `,"stack":[`...)
+		for i, line := range r.Stack {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, line)
+		}
+		b = append(b, ']')
+	}
+	for _, field := range r.Fields {
+		b = append(b, ',')
+		if jsonReservedKeys[field.Key] {
+			b = appendJSONString(b, "fields."+field.Key)
+		} else {
+			b = appendJSONString(b, field.Key)
+		}
+		b = append(b, ':')
+		b = appendJSONValue(b, field.Value)
+	}
+	return append(b, "}\n"...)
+}
+
+func appendJSONValue(b []byte, v any) []byte {
+	switch value := v.(type) {
+	case nil:
+		return append(b, "null"...)
+	case string:
+		return appendJSONString(b, value)
+	case bool:
+		return strconv.AppendBool(b, value)
+	case int:
+		return strconv.AppendInt(b, int64(value), 10)
+	case int64:
+		return strconv.AppendInt(b, value, 10)
+	case uint64:
+		return strconv.AppendUint(b, value, 10)
+	case error:
+		return appendJSONString(b, value.Error())
+	case json.Marshaler:
+	case fmt.Stringer:
+		return appendJSONString(b, value.String())
+	}
+
+	var raw []byte
+	var err error
+	if raw, err = json.Marshal(v); err != nil {
+		return appendJSONString(b, fmt.Sprint(v))
+	}
+	return append(b, raw...)
+}
+
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	var start int
+	for i := 0; i < len(s); {
+		var c byte = s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		var r rune
+		var size int
+		r, size = utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but break JavaScript consumers.
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, receiver, WithFormatter(new(JSONFormatter)))
+
+	logger.With("user_id", 42, "err", errors.New("boom"), "msg", "clash").LogError("struct", "function", "quote \" backslash \\ new line \n tab \t ctrl \x01 %s", 3, "<html>")
+	logger.LogInfo("struct", "function", "no id", -1)
+
+	var lines []string = strings.Split(strings.TrimSuffix(receiver.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("2 lines expected, got %d: %q", len(lines), receiver.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("invalid json %q: %s", lines[0], err)
+	}
+	var expected map[string]any = map[string]any{
+		"level":      "ERROR",
+		"structure":  "struct",
+		"function":   "function",
+		"id":         float64(3),
+		"msg":        "quote \" backslash \\ new line \n tab \t ctrl \x01 <html>",
+		"user_id":    float64(42),
+		"err":        "boom",
+		"fields.msg": "clash",
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("incorrect value for the key %s, got %v, expecting %v", key, entry[key], value)
+		}
+	}
+	if _, ok := entry["time"]; !ok {
+		t.Error("the key time is missing")
+	}
+
+	entry = nil
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("invalid json %q: %s", lines[1], err)
+	}
+	if _, ok := entry["id"]; ok {
+		t.Error("the key id must be omitted when the id is negative")
+	}
+}
+
+func TestAppendJSONString(t *testing.T) {
+	var tests map[string]string = map[string]string{
+		"simple":       `"simple"`,
+		"é\u2028":      `"é\u2028"`,
+		"bad \xff utf": `"bad \ufffd utf"`,
+	}
+	for in, expected := range tests {
+		if got := string(appendJSONString(nil, in)); got != expected {
+			t.Errorf("incorrect escaping of %q, got %s, expecting %s", in, got, expected)
+		}
+	}
+}
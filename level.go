@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var levelAliases map[string]LogLevel = map[string]LogLevel{
+	"OFF":       LogLevelNull,
+	"EMERG":     LogLevelEmerge,
+	"EMERGENCY": LogLevelEmerge,
+	"CRIT":      LogLevelCritical,
+	"ERR":       LogLevelError,
+	"WARN":      LogLevelWarning,
+}
+
+// ParseLogLevel returns the level named s, case insensitive. It accepts the
+// names returned by GetLevelName, the aliases OFF, EMERG, EMERGENCY, CRIT, ERR
+// and WARN, and the numbers, clamped as NewLogLevel does.
+func ParseLogLevel(s string) (LogLevel, error) {
+	s = strings.TrimSpace(s)
+	for level, name := range levelMap {
+		if strings.EqualFold(s, name) {
+			return level, nil
+		}
+	}
+	if level, exists := levelAliases[strings.ToUpper(s)]; exists {
+		return level, nil
+	}
+	var n uint64
+	var err error
+	if n, err = strconv.ParseUint(s, 10, 8); err != nil {
+		return 0, errors.New("logger: unknown level " + strconv.Quote(s))
+	}
+	return NewLogLevel(uint8(n)), nil
+}
+
+func (l LogLevel) String() string {
+	return GetLevelName(l)
+}
+
+func (l LogLevel) MarshalText() ([]byte, error) {
+	if _, exists := levelMap[l]; !exists {
+		return nil, errors.New("logger: invalid level " + strconv.Itoa(int(l)))
+	}
+	return []byte(GetLevelName(l)), nil
+}
+
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	var level LogLevel
+	var err error
+	if level, err = ParseLogLevel(string(text)); err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+func (l LogLevel) MarshalJSON() ([]byte, error) {
+	var text []byte
+	var err error
+	if text, err = l.MarshalText(); err != nil {
+		return nil, err
+	}
+	return strconv.AppendQuote(nil, string(text)), nil
+}
+
+// UnmarshalJSON accepts a level name or number.
+func (l *LogLevel) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	return l.UnmarshalText([]byte(s))
+}
+
+// Set implements flag.Value, so that a level can be given on the command line:
+//
+//	var level logger.LogLevel = logger.LogLevelInfo
+//	flag.Var(&level, "level", "the verbosity")
+func (l *LogLevel) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
+}
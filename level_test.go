@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	var tests = map[string]LogLevel{
+		"none":      LogLevelNull,
+		"OFF":       LogLevelNull,
+		"Emerge":    LogLevelEmerge,
+		"emerg":     LogLevelEmerge,
+		"EMERGENCY": LogLevelEmerge,
+		"alert":     LogLevelAlert,
+		"crit":      LogLevelCritical,
+		"CRITICAL":  LogLevelCritical,
+		"err":       LogLevelError,
+		"warn":      LogLevelWarning,
+		"Warning":   LogLevelWarning,
+		"notice":    LogLevelNotice,
+		" info\n":   LogLevelInfo,
+		"debug":     LogLevelDebug,
+		"TRACE":     LogLevelTrace,
+		"4":         LogLevelError,
+		"200":       LogLevelTrace,
+	}
+	for s, expected := range tests {
+		if level, err := ParseLogLevel(s); err != nil || level != expected {
+			t.Errorf("ParseLogLevel(%q) returned %s, %v, expecting %s", s, level, err, expected)
+		}
+	}
+	for _, s := range []string{"", "verbose", "-1", "256", "INFO2"} {
+		if _, err := ParseLogLevel(s); err == nil {
+			t.Errorf("ParseLogLevel(%q) must fail", s)
+		}
+	}
+}
+
+func TestLogLevelEncoding(t *testing.T) {
+	if s := fmt.Sprint(LogLevelWarning); s != "WARNING" {
+		t.Errorf("unexpected String %q", s)
+	}
+
+	var config struct {
+		Level LogLevel `json:"level"`
+	}
+	var data []byte
+	var err error
+	config.Level = LogLevelDebug
+	if data, err = json.Marshal(config); err != nil || string(data) != `{"level":"DEBUG"}` {
+		t.Errorf("unexpected JSON %s, %v", data, err)
+	}
+	if err = json.Unmarshal([]byte(`{"level":"warn"}`), &config); err != nil || config.Level != LogLevelWarning {
+		t.Errorf("unexpected level %s, %v", config.Level, err)
+	}
+	if err = json.Unmarshal([]byte(`{"level":3}`), &config); err != nil || config.Level != LogLevelCritical {
+		t.Errorf("unexpected level %s, %v", config.Level, err)
+	}
+	if err = json.Unmarshal([]byte(`{"level":"loud"}`), &config); err == nil {
+		t.Error("an unknown level must be refused")
+	}
+	if _, err = LogLevel(42).MarshalText(); err == nil {
+		t.Error("an invalid level must not be marshaled")
+	}
+
+	var level LogLevel = LogLevelInfo
+	var flags *flag.FlagSet = flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Var(&level, "level", "the verbosity")
+	if err = flags.Parse([]string{"-level", "trace"}); err != nil || level != LogLevelTrace {
+		t.Errorf("unexpected level %s, %v", level, err)
+	}
+}
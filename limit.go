@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimitConfig describes a token bucket limiting the records per level, or
+// per call site with PerKey. The bucket holds Burst tokens, refilled at Rate
+// tokens per second, and every record written takes one.
+type RateLimitConfig struct {
+	// Level is the most severe level limited, for instance LogLevelError for
+	// every level but EMERGE, ALERT and CRITICAL [LogLevelDebug]. The records
+	// of the FatalXxx methods are never limited.
+	Level LogLevel
+	// Rate is the number of records per second allowed in the long run, the
+	// limit is disabled when it is not positive.
+	Rate float64
+	// Burst is the number of records allowed at once [1].
+	Burst int
+	// PerKey gives a bucket to each structure, function and message template
+	// instead of each level.
+	PerKey bool
+}
+
+// WithRateLimit drops the records at or below config.Level exceeding the rate
+// limit before they are built.
+func WithRateLimit(config RateLimitConfig) Option {
+	return func(l *Logger) {
+		l.out.limiter = nil
+		if config.Rate > 0 {
+			l.out.limiter = newRateLimiter(config)
+		}
+	}
+}
+
+// Limited returns the number of records dropped by the rate limit.
+func (l *Logger) Limited() uint64 {
+	if l.out.limiter == nil {
+		return 0
+	}
+	return l.out.limiter.limited.Load()
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	config  RateLimitConfig
+	buckets sync.Map
+	keys    atomic.Int64
+	limited atomic.Uint64
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	if config.Level == LogLevelNull {
+		config.Level = LogLevelDebug
+	}
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+	return &rateLimiter{config: config}
+}
+
+// drop tells whether the record exceeds the rate limit and counts it.
+func (rl *rateLimiter) drop(level LogLevel, structure, function, msg string) bool {
+	if level < rl.config.Level {
+		return false
+	}
+	var key recordKey = recordKey{level: level}
+	if rl.config.PerKey && rl.keys.Load() < int64(samplingMaxKeys) {
+		key.structure, key.function, key.msg = structure, function, msg
+	}
+	if rl.bucket(key).take(time.Now(), rl.config.Rate, float64(rl.config.Burst)) {
+		return false
+	}
+	rl.limited.Add(1)
+	return true
+}
+
+func (rl *rateLimiter) bucket(key recordKey) *tokenBucket {
+	if b, ok := rl.buckets.Load(key); ok {
+		return b.(*tokenBucket)
+	}
+	var b any
+	var loaded bool
+	b, loaded = rl.buckets.LoadOrStore(key, &tokenBucket{tokens: float64(rl.config.Burst), last: time.Now()})
+	if !loaded {
+		rl.keys.Add(1)
+	}
+	return b.(*tokenBucket)
+}
+
+// take refills the bucket for the time elapsed since the last call and takes a
+// token if there is one.
+func (b *tokenBucket) take(now time.Time, rate, burst float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(burst, b.tokens+elapsed.Seconds()*rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithRateLimit(RateLimitConfig{Level: LogLevelError, Rate: 1, Burst: 3}))
+
+	for i := 0; i < 10; i++ {
+		logger.LogError("struct", "function", "error %d", -1, i)
+		logger.LogCritical("struct", "function", "critical %d", -1, i)
+	}
+	if n := strings.Count(buffer.String(), "error "); n != 3 || !strings.Contains(buffer.String(), "error 2\n") {
+		t.Errorf("expected the 3 first errors, got %q", buffer.String())
+	}
+	if n := strings.Count(buffer.String(), "critical "); n != 10 {
+		t.Errorf("the records above the limited level must not be limited, got %d", n)
+	}
+	if logger.Limited() != 7 {
+		t.Errorf("expected 7 limited records, got %d", logger.Limited())
+	}
+}
+
+func TestRateLimitPerKey(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithRateLimit(RateLimitConfig{Level: LogLevelError, Rate: 1, PerKey: true}))
+
+	for i := 0; i < 5; i++ {
+		logger.LogError("struct", "hot", "hot", -1)
+	}
+	logger.LogError("struct", "cold", "cold", -1)
+	if strings.Count(buffer.String(), "\n") != 2 || !strings.Contains(buffer.String(), "cold") {
+		t.Errorf("each call site must have its own bucket, got %q", buffer.String())
+	}
+}
+
+func TestRateLimitDefaults(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithRateLimit(RateLimitConfig{}))
+	for i := 0; i < 5; i++ {
+		logger.LogDebug("struct", "function", "debug", -1)
+	}
+	if strings.Count(buffer.String(), "\n") != 5 || logger.Limited() != 0 {
+		t.Errorf("a zero rate must disable the limit, got %q", buffer.String())
+	}
+
+	buffer.Reset()
+	logger = NewLogger(LogLevelTrace, buffer, WithRateLimit(RateLimitConfig{Rate: 1}))
+	for i := 0; i < 5; i++ {
+		logger.LogEmerge("struct", "function", "emerge", -1)
+		logger.LogInfo("struct", "function", "info", -1)
+		logger.LogDebug("struct", "function", "debug", -1)
+	}
+	if strings.Count(buffer.String(), "emerge") != 5 || strings.Count(buffer.String(), "info") != 5 || strings.Count(buffer.String(), "debug") != 1 {
+		t.Errorf("only the DEBUG and TRACE records must be limited by default, got %q", buffer.String())
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	var now time.Time = time.Now()
+	var b *tokenBucket = &tokenBucket{tokens: 2, last: now}
+	if !b.take(now, 10, 2) || !b.take(now, 10, 2) || b.take(now, 10, 2) {
+		t.Error("the bucket must allow the burst only")
+	}
+	if !b.take(now.Add(100*time.Millisecond), 10, 2) || b.take(now.Add(100*time.Millisecond), 10, 2) {
+		t.Error("the bucket must refill at the rate")
+	}
+	if !b.take(now.Add(time.Hour), 10, 2) || !b.take(now.Add(time.Hour), 10, 2) || b.take(now.Add(time.Hour), 10, 2) {
+		t.Error("the bucket must not refill above the burst")
+	}
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// LogfmtFormatter renders each record as a logfmt line:
+//
+//	ts=... level=ERROR structure=... function=... id=... msg="..." caller=... stack=...
+//
+// followed by the fields. The id is omitted when negative, the caller and the
+// stack, its lines joined by new lines, when not captured. Values are quoted
+// only when they are empty or contain spaces, '=', '"' or control characters.
+type LogfmtFormatter struct {
+	// TimeLayout is the layout used for the ts key, time.RFC3339Nano if empty.
+	TimeLayout string
+}
+
+func (f *LogfmtFormatter) Format(r *Record) []byte {
+	var layout string = f.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+
+	var b []byte = make([]byte, 0, 96+len(r.Structure)+len(r.Function)+len(r.Message))
+	b = append(b, "ts="...)
+	b = r.Time.AppendFormat(b, layout)
+	b = append(b, " level="...)
+	b = append(b, GetLevelName(r.Level)...)
+	b = append(b, " structure="...)
+	b = appendTextValue(b, r.Structure)
+	b = append(b, " function="...)
+	b = appendTextValue(b, r.Function)
+	if r.Id >= 0 {
+		b = append(b, " id="...)
+		b = strconv.AppendInt(b, int64(r.Id), 10)
+	}
+	b = append(b, " msg="...)
+	b = appendTextValue(b, r.Message)
+	if !r.Caller.IsZero() {
+		b = append(b, " caller="...)
+		b = appendTextValue(b, r.Caller.String())
+	}
+	if len(r.Stack) > 0 {
+		b = append(b, " stack="...)
+		b = appendTextValue(b, strings.Join(r.Stack, "\n"))
+	}
+	for _, field := range r.Fields {
+		b = append(b, ' ')
+		b = appendLogfmtKey(b, field.Key)
+		b = append(b, '=')
+		b = appendTextValue(b, fieldString(field.Value))
+	}
+	return append(b, '\n')
+}
+
+func appendLogfmtKey(b []byte, key string) []byte {
+	if key == "" {
+		return append(b, '_')
+	}
+	for _, c := range key {
+		if c <= ' ' || c == '=' || c == '"' || c == utf8.RuneError {
+			b = append(b, '_')
+		} else {
+			b = utf8.AppendRune(b, c)
+		}
+	}
+	return b
+}
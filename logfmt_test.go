@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtFormatter(t *testing.T) {
+	var date time.Time = time.Date(2023, 5, 17, 8, 4, 2, 0, time.UTC)
+	var formatter *LogfmtFormatter = new(LogfmtFormatter)
+	var r Record = Record{
+		Time:      date,
+		Level:     LogLevelError,
+		Structure: "struct",
+		Function:  "function",
+		Id:        3,
+		Message:   `say "hi" a=b`,
+		Fields:    []Field{{Key: "user id", Value: 42}, {Key: "empty", Value: ""}, {Key: "path", Value: "/tmp/x"}},
+	}
+	var expected string = `ts=2023-05-17T08:04:02Z level=ERROR structure=struct function=function id=3 msg="say \"hi\" a=b" user_id=42 empty="" path=/tmp/x` + "\n"
+	if got := string(formatter.Format(&r)); got != expected {
+		t.Errorf("incorrect logfmt line, got %q, expecting %q", got, expected)
+	}
+
+	r.Id = -1
+	r.Message = "plain"
+	r.Fields = nil
+	expected = "ts=2023-05-17T08:04:02Z level=ERROR structure=struct function=function msg=plain\n"
+	if got := string(formatter.Format(&r)); got != expected {
+		t.Errorf("incorrect logfmt line without id, got %q, expecting %q", got, expected)
+	}
+}
+
+func TestSetFormatter(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, receiver)
+
+	logger.LogInfo("struct", "function", "text", -1)
+	if !strings.HasSuffix(receiver.String(), "[INFO    ] struct -> function: text\n") {
+		t.Errorf("text output expected, got %q", receiver.String())
+	}
+
+	receiver.Reset()
+	logger.SetFormatter(new(LogfmtFormatter))
+	logger.LogInfo("struct", "function", "logfmt", -1)
+	if !strings.HasPrefix(receiver.String(), "ts=") || !strings.HasSuffix(receiver.String(), " level=INFO structure=struct function=function msg=logfmt\n") {
+		t.Errorf("logfmt output expected, got %q", receiver.String())
+	}
+}
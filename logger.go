@@ -1,9 +1,13 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -84,354 +88,341 @@ func NewLogLevel(l uint8) LogLevel {
 
 type logFunc func(structure, function, msg string, id int, vars ...any)
 
-type Logger struct {
-	logger           *log.Logger
-	defaultStructure string
-	defaultFunction  string
+type levelFunc func(l *Logger, structure, function, msg string, id int, vars []any)
+
+// logFuncs holds the closures built by SetVerbosity. It is shared by a Logger
+// and the loggers derived from it and replaced as a whole, so a verbosity
+// change is atomic for the callers.
+type logFuncs struct {
+	level    LogLevel
+	rules    []LevelRule
+	maxLevel LogLevel
+	cacheMu  sync.Mutex
+	cache    atomic.Pointer[map[levelKey]LogLevel]
 
 	// Log
-	logEmerge   logFunc
-	logAlert    logFunc
-	logCritical logFunc
-	logError    logFunc
-	logWarning  logFunc
-	logNotice   logFunc
-	logInfo     logFunc
-	logDebug    logFunc
-	logTrace    logFunc
+	logEmerge   levelFunc
+	logAlert    levelFunc
+	logCritical levelFunc
+	logError    levelFunc
+	logWarning  levelFunc
+	logNotice   levelFunc
+	logInfo     levelFunc
+	logDebug    levelFunc
+	logTrace    levelFunc
 
 	//Fatal
-	fatalEmerge   logFunc
-	fatalAlert    logFunc
-	fatalCritical logFunc
-	fatalError    logFunc
-	fatalWarning  logFunc
-	fatalNotice   logFunc
-	fatalInfo     logFunc
-	fatalDebug    logFunc
-	fatalTrace    logFunc
+	fatalEmerge   levelFunc
+	fatalAlert    levelFunc
+	fatalCritical levelFunc
+	fatalError    levelFunc
+	fatalWarning  levelFunc
+	fatalNotice   levelFunc
+	fatalInfo     levelFunc
+	fatalDebug    levelFunc
+	fatalTrace    levelFunc
+}
+
+type Logger struct {
+	out              *output
+	defaultStructure string
+	defaultFunction  string
+	fields           []Field
+	ctx              context.Context
 }
 
 func (l *Logger) SetDefaultStructure(s string) {
 	l.defaultStructure = s
+	l.out.setDefaultStructure(s)
 }
 func (l *Logger) SetDefaultFunction(s string) {
 	l.defaultFunction = s
 }
 
 func (l *Logger) LogEmerge(structure, function, msg string, id int, vars ...any) {
-	l.logEmerge(structure, function, msg, id, vars...)
+	l.out.funcs.Load().logEmerge(l, structure, function, msg, id, vars)
 }
 func (l *Logger) LogAlert(structure, function, msg string, id int, vars ...any) {
-	l.logAlert(structure, function, msg, id, vars...)
+	l.out.funcs.Load().logAlert(l, structure, function, msg, id, vars)
 }
 func (l *Logger) LogCritical(structure, function, msg string, id int, vars ...any) {
-	l.logCritical(structure, function, msg, id, vars...)
+	l.out.funcs.Load().logCritical(l, structure, function, msg, id, vars)
 }
 func (l *Logger) LogError(structure, function, msg string, id int, vars ...any) {
-	l.logError(structure, function, msg, id, vars...)
+	l.out.funcs.Load().logError(l, structure, function, msg, id, vars)
 }
 func (l *Logger) LogWarning(structure, function, msg string, id int, vars ...any) {
-	l.logWarning(structure, function, msg, id, vars...)
+	l.out.funcs.Load().logWarning(l, structure, function, msg, id, vars)
 }
 func (l *Logger) LogNotice(structure, function, msg string, id int, vars ...any) {
-	l.logNotice(structure, function, msg, id, vars...)
+	l.out.funcs.Load().logNotice(l, structure, function, msg, id, vars)
 }
 func (l *Logger) LogInfo(structure, function, msg string, id int, vars ...any) {
-	l.logInfo(structure, function, msg, id, vars...)
+	l.out.funcs.Load().logInfo(l, structure, function, msg, id, vars)
 }
 func (l *Logger) LogDebug(structure, function, msg string, id int, vars ...any) {
-	l.logDebug(structure, function, msg, id, vars...)
+	l.out.funcs.Load().logDebug(l, structure, function, msg, id, vars)
 }
 func (l *Logger) LogTrace(structure, function, msg string, id int, vars ...any) {
-	l.logTrace(structure, function, msg, id, vars...)
+	l.out.funcs.Load().logTrace(l, structure, function, msg, id, vars)
 }
 func (l *Logger) FatalEmerge(structure, function, msg string, id int, vars ...any) {
-	l.fatalEmerge(structure, function, msg, id, vars...)
-}
-func (l *Logger) FatalCritical(structure, function, msg string, id int, vars ...any) {
-	l.fatalCritical(structure, function, msg, id, vars...)
+	l.out.funcs.Load().fatalEmerge(l, structure, function, msg, id, vars)
 }
 func (l *Logger) FatalAlert(structure, function, msg string, id int, vars ...any) {
-	l.fatalAlert(structure, function, msg, id, vars...)
+	l.out.funcs.Load().fatalAlert(l, structure, function, msg, id, vars)
+}
+func (l *Logger) FatalCritical(structure, function, msg string, id int, vars ...any) {
+	l.out.funcs.Load().fatalCritical(l, structure, function, msg, id, vars)
 }
 func (l *Logger) FatalError(structure, function, msg string, id int, vars ...any) {
-	l.fatalError(structure, function, msg, id, vars...)
+	l.out.funcs.Load().fatalError(l, structure, function, msg, id, vars)
 }
 func (l *Logger) FatalWarning(structure, function, msg string, id int, vars ...any) {
-	l.fatalWarning(structure, function, msg, id, vars...)
+	l.out.funcs.Load().fatalWarning(l, structure, function, msg, id, vars)
 }
 func (l *Logger) FatalNotice(structure, function, msg string, id int, vars ...any) {
-	l.fatalNotice(structure, function, msg, id, vars...)
+	l.out.funcs.Load().fatalNotice(l, structure, function, msg, id, vars)
 }
 func (l *Logger) FatalInfo(structure, function, msg string, id int, vars ...any) {
-	l.fatalInfo(structure, function, msg, id, vars...)
+	l.out.funcs.Load().fatalInfo(l, structure, function, msg, id, vars)
 }
 func (l *Logger) FatalDebug(structure, function, msg string, id int, vars ...any) {
-	l.fatalDebug(structure, function, msg, id, vars...)
+	l.out.funcs.Load().fatalDebug(l, structure, function, msg, id, vars)
 }
 func (l *Logger) FatalTrace(structure, function, msg string, id int, vars ...any) {
-	l.fatalTrace(structure, function, msg, id, vars...)
+	l.out.funcs.Load().fatalTrace(l, structure, function, msg, id, vars)
 }
 func (l *Logger) Errorf(format string, args ...interface{}) {
-	l.logEmerge(l.defaultStructure, l.defaultFunction, format, -1, args...)
+	l.out.funcs.Load().logEmerge(l, l.defaultStructure, l.defaultFunction, format, -1, args)
 }
 
+// SetFormatter replaces the Formatter used to render the records. The
+// destination, and so the Formatter, is shared with the derived loggers.
+func (l *Logger) SetFormatter(f Formatter) {
+	l.out.setFormatter(f)
+}
+
+// SetVerbosity changes the verbosity of l and of the loggers derived from it.
+// It is safe to call it while other goroutines are logging.
 func (l *Logger) SetVerbosity(level LogLevel) {
-	for lvl := LogLevelNull; lvl <= LogLevelTrace; lvl++ {
-		switch lvl {
-		case LogLevelNull:
-			l.logEmerge = func(structure, function, msg string, id int, vars ...any) {}
-			l.logAlert = func(structure, function, msg string, id int, vars ...any) {}
-			l.logCritical = func(structure, function, msg string, id int, vars ...any) {}
-			l.logError = func(structure, function, msg string, id int, vars ...any) {}
-			l.logWarning = func(structure, function, msg string, id int, vars ...any) {}
-			l.logNotice = func(structure, function, msg string, id int, vars ...any) {}
-			l.logInfo = func(structure, function, msg string, id int, vars ...any) {}
-			l.logDebug = func(structure, function, msg string, id int, vars ...any) {}
-			l.logTrace = func(structure, function, msg string, id int, vars ...any) {}
-
-			l.fatalEmerge = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelEmergePrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelEmergePrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-			l.fatalAlert = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelAlertPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelAlertPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-			l.fatalCritical = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelCriticalPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelCriticalPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-			l.fatalError = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelErrorPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelErrorPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-			l.fatalWarning = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelWarningPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelWarningPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-			l.fatalNotice = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelNoticePrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelNoticePrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-			l.fatalInfo = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelInfoPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelInfoPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-			l.fatalDebug = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelDebugPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelDebugPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-			l.fatalTrace = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelTracePrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelTracePrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-		case LogLevelEmerge:
-			if lvl <= level {
-				l.logEmerge = func(structure, function, msg string, id int, vars ...any) {
-					if id < 0 {
-						l.logger.Printf(logPattern, logLevelEmergePrefix, structure, function, fmt.Sprintf(msg, vars...))
-					} else {
-						l.logger.Printf(logPatternWithId, logLevelEmergePrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-					}
-				}
-			} else {
-				l.logEmerge = func(structure, function, msg string, id int, vars ...any) {}
-			}
-			l.fatalEmerge = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelEmergePrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelEmergePrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-		case LogLevelAlert:
-			if lvl <= level {
-				l.logAlert = func(structure, function, msg string, id int, vars ...any) {
-					if id < 0 {
-						l.logger.Printf(logPattern, logLevelAlertPrefix, structure, function, fmt.Sprintf(msg, vars...))
-					} else {
-						l.logger.Printf(logPatternWithId, logLevelAlertPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-					}
-				}
-			} else {
-				l.logAlert = func(structure, function, msg string, id int, vars ...any) {}
-			}
-			l.fatalAlert = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelAlertPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelAlertPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-		case LogLevelCritical:
-			if lvl <= level {
-				l.logCritical = func(structure, function, msg string, id int, vars ...any) {
-					if id < 0 {
-						l.logger.Printf(logPattern, logLevelCriticalPrefix, structure, function, fmt.Sprintf(msg, vars...))
-					} else {
-						l.logger.Printf(logPatternWithId, logLevelCriticalPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-					}
-				}
-			} else {
-				l.logCritical = func(structure, function, msg string, id int, vars ...any) {}
-			}
-			l.fatalCritical = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelCriticalPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelCriticalPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-		case LogLevelError:
-			if lvl <= level {
-				l.logError = func(structure, function, msg string, id int, vars ...any) {
-					if id < 0 {
-						l.logger.Printf(logPattern, logLevelErrorPrefix, structure, function, fmt.Sprintf(msg, vars...))
-					} else {
-						l.logger.Printf(logPatternWithId, logLevelErrorPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-					}
-				}
-			} else {
-				l.logError = func(structure, function, msg string, id int, vars ...any) {}
-			}
-			l.fatalError = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelErrorPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelErrorPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-		case LogLevelWarning:
-			if lvl <= level {
-				l.logWarning = func(structure, function, msg string, id int, vars ...any) {
-					if id < 0 {
-						l.logger.Printf(logPattern, logLevelWarningPrefix, structure, function, fmt.Sprintf(msg, vars...))
-					} else {
-						l.logger.Printf(logPatternWithId, logLevelWarningPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-					}
-				}
-			} else {
-				l.logWarning = func(structure, function, msg string, id int, vars ...any) {}
-			}
-			l.fatalWarning = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelWarningPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelWarningPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-		case LogLevelNotice:
-			if lvl <= level {
-				l.logNotice = func(structure, function, msg string, id int, vars ...any) {
-					if id < 0 {
-						l.logger.Printf(logPattern, logLevelNoticePrefix, structure, function, fmt.Sprintf(msg, vars...))
-					} else {
-						l.logger.Printf(logPatternWithId, logLevelNoticePrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-					}
-				}
-			} else {
-				l.logNotice = func(structure, function, msg string, id int, vars ...any) {}
-			}
-			l.fatalNotice = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelNoticePrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelNoticePrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-		case LogLevelInfo:
-			if lvl <= level {
-				l.logInfo = func(structure, function, msg string, id int, vars ...any) {
-					if id < 0 {
-						l.logger.Printf(logPattern, logLevelInfoPrefix, structure, function, fmt.Sprintf(msg, vars...))
-					} else {
-						l.logger.Printf(logPatternWithId, logLevelInfoPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-					}
-				}
-			} else {
-				l.logInfo = func(structure, function, msg string, id int, vars ...any) {}
-			}
-			l.fatalInfo = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelInfoPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelInfoPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-		case LogLevelDebug:
-			if lvl <= level {
-				l.logDebug = func(structure, function, msg string, id int, vars ...any) {
-					if id < 0 {
-						l.logger.Printf(logPattern, logLevelDebugPrefix, structure, function, fmt.Sprintf(msg, vars...))
-					} else {
-						l.logger.Printf(logPatternWithId, logLevelDebugPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-					}
-				}
-			} else {
-				l.logDebug = func(structure, function, msg string, id int, vars ...any) {}
-			}
-			l.fatalDebug = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelDebugPrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelDebugPrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
-		case LogLevelTrace:
-			if lvl <= level {
-				l.logTrace = func(structure, function, msg string, id int, vars ...any) {
-					if id < 0 {
-						l.logger.Printf(logPattern, logLevelTracePrefix, structure, function, fmt.Sprintf(msg, vars...))
-					} else {
-						l.logger.Printf(logPatternWithId, logLevelTracePrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-					}
-				}
-			} else {
-				l.logTrace = func(structure, function, msg string, id int, vars ...any) {}
-			}
-			l.fatalTrace = func(structure, function, msg string, id int, vars ...any) {
-				if id < 0 {
-					l.logger.Fatalf(logPattern, logLevelTracePrefix, structure, function, fmt.Sprintf(msg, vars...))
-				} else {
-					l.logger.Fatalf(logPatternWithId, logLevelTracePrefix, structure, function, id, fmt.Sprintf(msg, vars...))
-				}
-			}
+	l.out.setVerbosity(level)
+}
+
+// Verbosity returns the current verbosity of l, the level rules may enable
+// more or less severe records for some structures and functions.
+func (l *Logger) Verbosity() LogLevel {
+	return l.out.funcs.Load().level
+}
+
+func (o *output) setVerbosity(level LogLevel) {
+	o.levelMu.Lock()
+	defer o.levelMu.Unlock()
+	o.level = level
+	o.buildFuncs()
+}
+
+// buildFuncs replaces the closures after a change of the verbosity or of the
+// level rules, o.levelMu must be held.
+func (o *output) buildFuncs() {
+	var f *logFuncs = newLogFuncs(o.level, o.rules)
+
+	f.logEmerge = f.newLogFunc(LogLevelEmerge)
+	f.logAlert = f.newLogFunc(LogLevelAlert)
+	f.logCritical = f.newLogFunc(LogLevelCritical)
+	f.logError = f.newLogFunc(LogLevelError)
+	f.logWarning = f.newLogFunc(LogLevelWarning)
+	f.logNotice = f.newLogFunc(LogLevelNotice)
+	f.logInfo = f.newLogFunc(LogLevelInfo)
+	f.logDebug = f.newLogFunc(LogLevelDebug)
+	f.logTrace = f.newLogFunc(LogLevelTrace)
+
+	f.fatalEmerge = newFatalFunc(LogLevelEmerge)
+	f.fatalAlert = newFatalFunc(LogLevelAlert)
+	f.fatalCritical = newFatalFunc(LogLevelCritical)
+	f.fatalError = newFatalFunc(LogLevelError)
+	f.fatalWarning = newFatalFunc(LogLevelWarning)
+	f.fatalNotice = newFatalFunc(LogLevelNotice)
+	f.fatalInfo = newFatalFunc(LogLevelInfo)
+	f.fatalDebug = newFatalFunc(LogLevelDebug)
+	f.fatalTrace = newFatalFunc(LogLevelTrace)
+
+	o.funcs.Store(f)
+}
+
+func (f *logFuncs) newLogFunc(lvl LogLevel) levelFunc {
+	if lvl > f.maxLevel {
+		return func(l *Logger, structure, function, msg string, id int, vars []any) {}
+	}
+	if len(f.rules) == 0 {
+		return func(l *Logger, structure, function, msg string, id int, vars []any) {
+			l.log(lvl, structure, function, msg, id, vars)
+		}
+	}
+	return func(l *Logger, structure, function, msg string, id int, vars []any) {
+		// The rules of the filled records are checked by log, once the names
+		// are known.
+		if lvl <= f.levelOf(structure, function) || l.out.fills(structure, function) {
+			l.log(lvl, structure, function, msg, id, vars)
+		}
+	}
+}
+
+func newFatalFunc(lvl LogLevel) levelFunc {
+	return func(l *Logger, structure, function, msg string, id int, vars []any) {
+		var r Record = l.newRecord(l.ctx, lvl, structure, function, msg, id, vars, callerSkipDirect)
+		r.fatal = true
+		if l.out.stack != nil && lvl == LogLevelEmerge {
+			r.Stack = dumpGoroutines()
+		} else if l.out.stack != nil && r.Stack == nil {
+			// runtime.Callers, captureStack, the closure and FatalXxx.
+			r.Stack = captureStack(callerSkipDirect - 1)
+		}
+		l.out.write(&r)
+		l.out.fatal()
+	}
+}
+
+func (l *Logger) log(level LogLevel, structure, function, msg string, id int, vars []any) {
+	if l.out.fills(structure, function) {
+		structure, function = l.out.fillNames(structure, function, callerSkipLog)
+		if !l.out.funcs.Load().enabled(level, structure, function) {
+			return
+		}
+	}
+	if l.out.drop(level, structure, function, msg) {
+		return
+	}
+	var r Record = l.newRecord(l.ctx, level, structure, function, msg, id, vars, callerSkipLog)
+	l.out.write(&r)
+}
+
+// newRecord builds a record, skip is the number of frames above
+// runtime.Callers to reach the caller of the Logger.
+func (l *Logger) newRecord(ctx context.Context, level LogLevel, structure, function, msg string, id int, vars []any, skip int) Record {
+	var r Record = Record{
+		Time:      time.Now(),
+		Level:     level,
+		Structure: structure,
+		Function:  function,
+		Id:        id,
+		Message:   fmt.Sprintf(msg, vars...),
+		Fields:    l.out.contextFields(ctx, l.fields),
+		Context:   ctx,
+	}
+	if l.out.caller != nil {
+		r.Caller = l.out.captureCaller(skip)
+		if l.out.caller.Fill {
+			fillFromCaller(&r)
+		}
+	}
+	if l.out.stack != nil && level <= l.out.stack.Level {
+		r.Stack = captureStack(skip)
+	}
+	return r
+}
+
+// output is the state shared by a Logger and the loggers derived from it.
+type output struct {
+	mu               sync.Mutex
+	levelMu          sync.Mutex
+	level            LogLevel
+	rules            []LevelRule
+	funcs            atomic.Pointer[logFuncs]
+	primary          *sink
+	sinks            []*sink
+	defaultStructure string
+	async            *asyncQueue
+	extractorsMu     sync.RWMutex
+	extractors       []ContextExtractor
+	trace            *TraceConfig
+	caller           *CallerConfig
+	stack            *StackConfig
+	sampler          *sampler
+	limiter          *rateLimiter
+	dedup            *deduplicator
+	redactor         *redactor
+	fatalMu          sync.Mutex
+	fatalHooks       []func()
+	fatalTimeout     time.Duration
+	exitCode         int
+	exit             func(code int)
+	closers          []io.Closer
+	configSinks      []*sink
+	configClosers    []io.Closer
+}
+
+func newOutput(dst io.Writer, f Formatter) *output {
+	var o *output = &output{fatalTimeout: defaultFatalTimeout, exitCode: 1, exit: os.Exit}
+	if dst != nil {
+		o.primary = newSink(dst, LogLevelTrace, f)
+		o.sinks = []*sink{o.primary}
+	}
+	return o
+}
+
+func (o *output) setFormatter(f Formatter) {
+	o.mu.Lock()
+	if o.primary != nil {
+		o.primary.formatter = f
+	}
+	o.mu.Unlock()
+}
+
+// drop tells whether a record is suppressed by the sampling or the rate limit,
+// before it is built.
+func (o *output) drop(level LogLevel, structure, function, msg string) bool {
+	if o.sampler != nil && o.sampler.drop(level, structure, function, msg) {
+		return true
+	}
+	return o.limiter != nil && o.limiter.drop(level, structure, function, msg)
+}
+
+func (o *output) write(r *Record) {
+	if o.redactor != nil {
+		o.redactor.redactRecord(r)
+	}
+	if o.dedup != nil {
+		o.dedup.write(r)
+		return
+	}
+	o.emit(r)
+}
+
+func (o *output) emit(r *Record) {
+	o.recordSpanEvent(r)
+	if o.async != nil && o.async.enqueue(r) {
+		return
+	}
+	o.writeSync(r)
+}
+
+func (o *output) writeSync(r *Record) {
+	o.mu.Lock()
+	for _, s := range o.sinks {
+		if r.Level <= s.level || r.fatal {
+			s.write(r)
 		}
 	}
+	o.mu.Unlock()
 }
 
-func NewLogger(level LogLevel, dst io.Writer) *Logger {
+type Option func(l *Logger)
+
+// WithFormatter sets the Formatter used to render the records, the default is
+// a TextFormatter.
+func WithFormatter(f Formatter) Option {
+	return func(l *Logger) {
+		l.out.setFormatter(f)
+	}
+}
+
+func NewLogger(level LogLevel, dst io.Writer, opts ...Option) *Logger {
 	var l *Logger = new(Logger)
-	l.logger = log.New(dst, "", log.Ldate|log.Lmicroseconds)
+	l.out = newOutput(dst, new(TextFormatter))
+	for _, opt := range opts {
+		opt(l)
+	}
 	l.SetVerbosity(level)
 	return l
 }
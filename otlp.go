@@ -0,0 +1,480 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	otlpDefaultBatchSize      int           = 512
+	otlpDefaultQueueSize      int           = 2048
+	otlpDefaultFlushInterval  time.Duration = time.Second
+	otlpDefaultMaxRetries     int           = 5
+	otlpDefaultInitialBackoff time.Duration = 100 * time.Millisecond
+	otlpDefaultMaxBackoff     time.Duration = 5 * time.Second
+	otlpDefaultTimeout        time.Duration = 10 * time.Second
+	otlpScopeName             string        = "github.com/mmaFR/logger"
+)
+
+var otlpSeverityMap map[LogLevel]int = map[LogLevel]int{
+	LogLevelEmerge:   22, // FATAL2
+	LogLevelAlert:    21, // FATAL
+	LogLevelCritical: 19, // ERROR3
+	LogLevelError:    17, // ERROR
+	LogLevelWarning:  13, // WARN
+	LogLevelNotice:   10, // INFO2
+	LogLevelInfo:     9,  // INFO
+	LogLevelDebug:    5,  // DEBUG
+	LogLevelTrace:    1,  // TRACE
+}
+
+// OTLPConfig describes the collector an OTLPExporter sends the records to.
+// The zero values are replaced by the defaults given in brackets.
+type OTLPConfig struct {
+	// Endpoint is the full URL of the OTLP/HTTP logs endpoint, for instance
+	// http://localhost:4318/v1/logs.
+	Endpoint string
+	Headers  map[string]string
+	// ServiceName is sent as the service.name resource attribute [the default
+	// structure of the Logger, then the name of the executable].
+	ServiceName string
+	// ResourceAttributes are added to the resource of every export.
+	ResourceAttributes []Field
+	// BatchSize is the maximum number of records per export [512].
+	BatchSize int
+	// QueueSize is the number of records waiting for an export above which the
+	// new records are dropped [2048].
+	QueueSize int
+	// FlushInterval is the maximum time a record waits for an export [1s].
+	FlushInterval time.Duration
+	// MaxRetries is the number of retries of a failed export [5], a negative
+	// value disables the retries.
+	MaxRetries     int
+	InitialBackoff time.Duration // [100ms]
+	MaxBackoff     time.Duration // [5s]
+	// Timeout bounds each HTTP request [10s].
+	Timeout time.Duration
+	Client  *http.Client
+	// SpanContext returns the span active in the context of a record, to fill
+	// the trace id and span id [SpanContextFromContext].
+	SpanContext func(ctx context.Context) (SpanContext, bool)
+}
+
+// OTLPExporter is a destination exporting the records as OpenTelemetry log
+// records over OTLP/HTTP with the JSON encoding. The records are batched and
+// exported by a background goroutine, the failed exports are retried with an
+// exponential backoff on network errors and on the 429, 502, 503 and 504
+// status codes.
+type OTLPExporter struct {
+	mu               sync.Mutex
+	exportMu         sync.Mutex
+	config           OTLPConfig
+	pending          []otlpLogRecord
+	defaultStructure string
+	kick             chan struct{}
+	done             chan struct{}
+	stopped          chan struct{}
+	closed           bool
+	dropped          atomic.Uint64
+	failed           atomic.Uint64
+}
+
+func NewOTLPExporter(config OTLPConfig) (*OTLPExporter, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("logger: the OTLP endpoint cannot be empty")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = otlpDefaultBatchSize
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = otlpDefaultQueueSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = otlpDefaultFlushInterval
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = otlpDefaultMaxRetries
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = otlpDefaultInitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = otlpDefaultMaxBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = otlpDefaultTimeout
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.SpanContext == nil {
+		config.SpanContext = SpanContextFromContext
+	}
+
+	var e *OTLPExporter = &OTLPExporter{
+		config:  config,
+		kick:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go e.run()
+	return e, nil
+}
+
+// NewOTLPLogger returns a Logger writing to a new OTLPExporter, closed by
+// Logger.Close.
+func NewOTLPLogger(level LogLevel, config OTLPConfig, opts ...Option) (*Logger, error) {
+	var e *OTLPExporter
+	var err error
+	if e, err = NewOTLPExporter(config); err != nil {
+		return nil, err
+	}
+	opts = append([]Option{WithFormatter(noFormatter{})}, opts...)
+	var l *Logger = NewLogger(level, e, opts...)
+	l.out.closers = append(l.out.closers, e)
+	return l, nil
+}
+
+func (e *OTLPExporter) setDefaultStructure(s string) {
+	e.mu.Lock()
+	e.defaultStructure = s
+	e.mu.Unlock()
+}
+
+// Write exports p as the body of a record with the severity of LogLevelInfo.
+func (e *OTLPExporter) Write(p []byte) (int, error) {
+	var r Record = Record{Time: time.Now(), Level: LogLevelInfo, Id: -1, Message: string(bytes.TrimRight(p, "\n"))}
+	return e.WriteRecord(&r, p)
+}
+
+func (e *OTLPExporter) WriteRecord(r *Record, p []byte) (int, error) {
+	var record otlpLogRecord = e.convert(r)
+
+	e.mu.Lock()
+	if e.closed || len(e.pending) >= e.config.QueueSize {
+		e.mu.Unlock()
+		e.dropped.Add(1)
+		return len(p), nil
+	}
+	e.pending = append(e.pending, record)
+	var full bool = len(e.pending) >= e.config.BatchSize
+	e.mu.Unlock()
+
+	if full {
+		select {
+		case e.kick <- struct{}{}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// Flush exports the pending records on the calling goroutine, after the
+// export in progress in the background if any.
+func (e *OTLPExporter) Flush() error {
+	e.exportMu.Lock()
+	defer e.exportMu.Unlock()
+
+	var err error
+	for {
+		var batch []otlpLogRecord = e.takeBatch()
+		if len(batch) == 0 {
+			return err
+		}
+		if exportErr := e.export(batch); exportErr != nil {
+			err = exportErr
+		}
+	}
+}
+
+// Close exports the pending records and stops the background goroutine, the
+// records written afterwards are dropped.
+func (e *OTLPExporter) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	e.mu.Unlock()
+
+	close(e.done)
+	<-e.stopped
+	return e.Flush()
+}
+
+// Dropped returns the number of records dropped because the queue was full or
+// because their export failed after the retries.
+func (e *OTLPExporter) Dropped() uint64 {
+	return e.dropped.Load() + e.failed.Load()
+}
+
+func (e *OTLPExporter) run() {
+	defer close(e.stopped)
+	var ticker *time.Ticker = time.NewTicker(e.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+			e.Flush()
+		case <-e.kick:
+			e.Flush()
+		}
+	}
+}
+
+func (e *OTLPExporter) takeBatch() []otlpLogRecord {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var n int = len(e.pending)
+	if n > e.config.BatchSize {
+		n = e.config.BatchSize
+	}
+	var batch []otlpLogRecord = make([]otlpLogRecord, n)
+	copy(batch, e.pending)
+	e.pending = e.pending[:copy(e.pending, e.pending[n:])]
+	return batch
+}
+
+// export must be called with exportMu held, so that the batches are sent one
+// at a time and in order.
+func (e *OTLPExporter) export(batch []otlpLogRecord) error {
+	var body []byte
+	var err error
+	if body, err = json.Marshal(e.request(batch)); err != nil {
+		e.failed.Add(uint64(len(batch)))
+		return err
+	}
+
+	var backoff time.Duration = e.config.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		var retryAfter time.Duration
+		var retryable bool
+		if retryAfter, retryable, err = e.post(body); err == nil {
+			return nil
+		}
+		if !retryable || attempt >= e.config.MaxRetries {
+			e.failed.Add(uint64(len(batch)))
+			return err
+		}
+		if retryAfter <= 0 {
+			retryAfter = backoff
+		}
+		time.Sleep(retryAfter)
+		if backoff *= 2; backoff > e.config.MaxBackoff {
+			backoff = e.config.MaxBackoff
+		}
+	}
+}
+
+// post sends body and tells whether a failure can be retried, and after which
+// delay when the collector asked for one with Retry-After.
+func (e *OTLPExporter) post(body []byte) (time.Duration, bool, error) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(context.Background(), e.config.Timeout)
+	defer cancel()
+
+	var req *http.Request
+	var err error
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(body)); err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	var resp *http.Response
+	if resp, err = e.config.Client.Do(req); err != nil {
+		return 0, true, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, false, nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable, resp.StatusCode == http.StatusGatewayTimeout:
+		var retryAfter time.Duration
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return retryAfter, true, fmt.Errorf("logger: OTLP export failed with the status %s", resp.Status)
+	default:
+		return 0, false, fmt.Errorf("logger: OTLP export failed with the status %s", resp.Status)
+	}
+}
+
+func (e *OTLPExporter) serviceName() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch {
+	case e.config.ServiceName != "":
+		return e.config.ServiceName
+	case e.defaultStructure != "":
+		return e.defaultStructure
+	default:
+		return filepath.Base(os.Args[0])
+	}
+}
+
+func (e *OTLPExporter) convert(r *Record) otlpLogRecord {
+	var record otlpLogRecord = otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(r.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       otlpSeverityMap[r.Level],
+		SeverityText:         GetLevelName(r.Level),
+		Body:                 otlpAnyValue{StringValue: &r.Message},
+		Attributes:           make([]otlpKeyValue, 0, 3+len(r.Fields)),
+	}
+	record.Attributes = append(record.Attributes,
+		otlpAttribute("structure", r.Structure),
+		otlpAttribute("function", r.Function),
+	)
+	if r.Id >= 0 {
+		record.Attributes = append(record.Attributes, otlpAttribute("id", r.Id))
+	}
+	if !r.Caller.IsZero() {
+		record.Attributes = append(record.Attributes,
+			otlpAttribute("code.filepath", r.Caller.File),
+			otlpAttribute("code.lineno", r.Caller.Line),
+			otlpAttribute("code.function", r.Caller.Function),
+		)
+	}
+	if len(r.Stack) > 0 {
+		record.Attributes = append(record.Attributes, otlpAttribute("exception.stacktrace", strings.Join(r.Stack, "\n")))
+	}
+	for _, field := range r.Fields {
+		if field.Key == traceFieldTraceId || field.Key == traceFieldSpanId || field.Key == traceFieldTraceFlags {
+			continue
+		}
+		record.Attributes = append(record.Attributes, otlpAttribute(field.Key, field.Value))
+	}
+	if r.Context != nil {
+		if sc, ok := e.config.SpanContext(r.Context); ok && sc.IsValid() {
+			record.TraceId = hex.EncodeToString(sc.TraceId[:])
+			record.SpanId = hex.EncodeToString(sc.SpanId[:])
+			record.Flags = uint32(sc.TraceFlags)
+		}
+	}
+	return record
+}
+
+func (e *OTLPExporter) request(batch []otlpLogRecord) otlpRequest {
+	var resource []otlpKeyValue = []otlpKeyValue{otlpAttribute("service.name", e.serviceName())}
+	for _, field := range e.config.ResourceAttributes {
+		resource = append(resource, otlpAttribute(field.Key, field.Value))
+	}
+	return otlpRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource: otlpResource{Attributes: resource},
+		ScopeLogs: []otlpScopeLogs{{
+			Scope:      otlpScope{Name: otlpScopeName},
+			LogRecords: batch,
+		}},
+	}}}
+}
+
+// The types below follow the JSON encoding of the OTLP protobuf messages, in
+// which the 64 bits integers are strings and the ids are hexadecimal.
+
+type otlpRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes"`
+	TraceId              string         `json:"traceId,omitempty"`
+	SpanId               string         `json:"spanId,omitempty"`
+	Flags                uint32         `json:"flags,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func otlpAttribute(key string, value any) otlpKeyValue {
+	var v otlpAnyValue
+	switch typed := value.(type) {
+	case string:
+		v.StringValue = &typed
+	case bool:
+		v.BoolValue = &typed
+	case int:
+		var s string = strconv.FormatInt(int64(typed), 10)
+		v.IntValue = &s
+	case int32:
+		var s string = strconv.FormatInt(int64(typed), 10)
+		v.IntValue = &s
+	case int64:
+		var s string = strconv.FormatInt(typed, 10)
+		v.IntValue = &s
+	case uint32:
+		var s string = strconv.FormatUint(uint64(typed), 10)
+		v.IntValue = &s
+	case float32:
+		return otlpAttribute(key, float64(typed))
+	case float64:
+		// NaN and the infinities have no JSON encoding.
+		if math.IsNaN(typed) || math.IsInf(typed, 0) {
+			var s string = strconv.FormatFloat(typed, 'g', -1, 64)
+			v.StringValue = &s
+		} else {
+			v.DoubleValue = &typed
+		}
+	default:
+		var s string = fieldString(value)
+		v.StringValue = &s
+	}
+	return otlpKeyValue{Key: key, Value: v}
+}
+
+var _ RecordWriter = (*OTLPExporter)(nil)
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type otlpCollector struct {
+	mu       sync.Mutex
+	failures int
+	requests []otlpRequest
+	headers  []http.Header
+}
+
+func (c *otlpCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures > 0 {
+		c.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var body []byte
+	body, _ = io.ReadAll(r.Body)
+	var req otlpRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.requests = append(c.requests, req)
+	c.headers = append(c.headers, r.Header.Clone())
+}
+
+func (c *otlpCollector) records() []otlpLogRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var records []otlpLogRecord
+	for _, req := range c.requests {
+		records = append(records, req.ResourceLogs[0].ScopeLogs[0].LogRecords...)
+	}
+	return records
+}
+
+func otlpAttributes(record otlpLogRecord) map[string]string {
+	var attributes map[string]string = make(map[string]string)
+	for _, kv := range record.Attributes {
+		switch {
+		case kv.Value.StringValue != nil:
+			attributes[kv.Key] = *kv.Value.StringValue
+		case kv.Value.IntValue != nil:
+			attributes[kv.Key] = *kv.Value.IntValue
+		}
+	}
+	return attributes
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var collector *otlpCollector = &otlpCollector{failures: 2}
+	var server *httptest.Server = httptest.NewServer(collector)
+	defer server.Close()
+
+	var logger *Logger
+	var err error
+	if logger, err = NewOTLPLogger(LogLevelInfo, OTLPConfig{
+		Endpoint:       server.URL + "/v1/logs",
+		Headers:        map[string]string{"Authorization": "Bearer token"},
+		BatchSize:      2,
+		FlushInterval:  time.Hour,
+		InitialBackoff: time.Millisecond,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	logger.SetDefaultStructure("myservice")
+
+	var ctx context.Context
+	ctx, _ = ContextWithTraceparent(context.Background(), testTraceparent)
+	logger.With("user_id", 42).LogErrorCtx(ctx, "db", "Query", "failed after %d tries", 7, 3)
+	logger.LogInfo("http", "Serve", "listening", -1)
+	logger.LogNotice("http", "Serve", "pending", -1)
+	if err = logger.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []otlpLogRecord = collector.records()
+	if len(records) != 3 {
+		t.Fatalf("3 records expected, got %d", len(records))
+	}
+	if len(collector.requests) != 2 {
+		t.Errorf("the records must be sent in batches of 2, got %d requests", len(collector.requests))
+	}
+	if collector.headers[0].Get("Authorization") != "Bearer token" {
+		t.Error("the headers are missing")
+	}
+	if name := *collector.requests[0].ResourceLogs[0].Resource.Attributes[0].Value.StringValue; name != "myservice" {
+		t.Errorf("the service name must default to the default structure, got %q", name)
+	}
+
+	var record otlpLogRecord = records[0]
+	if record.SeverityNumber != 17 || record.SeverityText != "ERROR" || *record.Body.StringValue != "failed after 3 tries" {
+		t.Errorf("incorrect record %+v", record)
+	}
+	if record.TraceId != "4bf92f3577b34da6a3ce929d0e0e4736" || record.SpanId != "00f067aa0ba902b7" || record.Flags != 1 {
+		t.Errorf("incorrect trace correlation %+v", record)
+	}
+	var attributes map[string]string = otlpAttributes(record)
+	var expected map[string]string = map[string]string{"structure": "db", "function": "Query", "id": "7", "user_id": "42"}
+	for key, value := range expected {
+		if attributes[key] != value {
+			t.Errorf("incorrect attribute %s, got %q, expecting %q", key, attributes[key], value)
+		}
+	}
+	if _, ok := otlpAttributes(records[1])["id"]; ok {
+		t.Error("the id attribute must be omitted when the id is negative")
+	}
+	if err = logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var exporter *OTLPExporter = logger.out.primary.writer.(*OTLPExporter)
+	select {
+	case <-exporter.stopped:
+	default:
+		t.Error("Close must stop the exporter created by NewOTLPLogger")
+	}
+}
+
+func TestOTLPExporterGiveUp(t *testing.T) {
+	var collector *otlpCollector = &otlpCollector{failures: 10}
+	var server *httptest.Server = httptest.NewServer(collector)
+	defer server.Close()
+
+	var exporter *OTLPExporter
+	var err error
+	if exporter, err = NewOTLPExporter(OTLPConfig{Endpoint: server.URL, MaxRetries: 2, InitialBackoff: time.Millisecond, FlushInterval: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	NewLogger(LogLevelInfo, exporter).LogInfo("struct", "function", "lost", -1)
+	if err = exporter.Close(); err == nil {
+		t.Error("the export must fail after the retries")
+	}
+	if exporter.Dropped() != 1 || collector.failures != 7 {
+		t.Errorf("1 dropped record after 3 attempts expected, got %d dropped and %d remaining failures", exporter.Dropped(), collector.failures)
+	}
+}
@@ -0,0 +1,245 @@
+package logger
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+const redactionDefaultReplacement string = "[REDACTED]"
+
+// RedactionDetector is a set of built-in detectors of secrets and personal
+// data.
+type RedactionDetector uint16
+
+const (
+	// RedactBearer detects the tokens following "Bearer", the scheme is kept.
+	RedactBearer RedactionDetector = 1 << iota
+	// RedactAWSKey detects the AWS access key ids and the secret access keys
+	// assigned to a key named like aws_secret_access_key.
+	RedactAWSKey
+	// RedactCreditCard detects the card numbers of 13 to 19 digits, possibly
+	// grouped by spaces or dashes, passing the Luhn check.
+	RedactCreditCard
+	// RedactEmail detects the e-mail addresses.
+	RedactEmail
+	// RedactIP detects the IPv4 and IPv6 addresses.
+	RedactIP
+
+	RedactAll RedactionDetector = RedactBearer | RedactAWSKey | RedactCreditCard | RedactEmail | RedactIP
+)
+
+// RedactionConfig describes the data removed from the records.
+type RedactionConfig struct {
+	// Detectors are the built-in detectors used, RedactAll for all of them.
+	Detectors RedactionDetector
+	// Patterns are additional expressions whose matches are redacted.
+	Patterns []*regexp.Regexp
+	// DenyFields are the keys of the fields whose value is always redacted,
+	// compared without case to the whole key and to its last dot separated
+	// part, so that "password" denies "request.password".
+	DenyFields []string
+	// Replacement replaces the redacted data, "[REDACTED]" if empty.
+	Replacement string
+}
+
+// WithRedaction redacts the message and the fields of the records before they
+// reach any sink. The string fields, the errors and the fmt.Stringer values
+// are scanned; a value that needs redacting is replaced by its redacted
+// string, except the error chains of Err which keep their structure.
+func WithRedaction(config RedactionConfig) Option {
+	return func(l *Logger) {
+		l.out.redactor = newRedactor(config)
+	}
+}
+
+// redactionRule replaces the submatch group of the matches of re accepted by
+// valid.
+type redactionRule struct {
+	re    *regexp.Regexp
+	group int
+	valid func(match string) bool
+}
+
+var builtinRedactionRules = []struct {
+	detector RedactionDetector
+	rule     redactionRule
+}{
+	{RedactBearer, redactionRule{re: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`), group: 1}},
+	{RedactAWSKey, redactionRule{re: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}\b`)}},
+	{RedactAWSKey, redactionRule{re: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`), group: 1}},
+	{RedactCreditCard, redactionRule{re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhnValid}},
+	{RedactEmail, redactionRule{re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)}},
+	// The IPv6 candidates are whole tokens of word characters, colons and dots,
+	// so that "std::vector" or "deadbeef::1" are left untouched, and they come
+	// first to redact the IPv4-mapped addresses at once.
+	{RedactIP, redactionRule{re: regexp.MustCompile(`([\w.]*:[\w:.]*?)\.*(?:[^\w:.]|$)`), group: 1, valid: ipv6Valid}},
+	{RedactIP, redactionRule{re: regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`), valid: ipValid}},
+}
+
+type redactor struct {
+	rules       []redactionRule
+	deny        map[string]struct{}
+	replacement string
+}
+
+func newRedactor(config RedactionConfig) *redactor {
+	var r *redactor = &redactor{deny: make(map[string]struct{}, len(config.DenyFields)), replacement: config.Replacement}
+	if r.replacement == "" {
+		r.replacement = redactionDefaultReplacement
+	}
+	for _, builtin := range builtinRedactionRules {
+		if config.Detectors&builtin.detector != 0 {
+			r.rules = append(r.rules, builtin.rule)
+		}
+	}
+	for _, re := range config.Patterns {
+		r.rules = append(r.rules, redactionRule{re: re})
+	}
+	for _, key := range config.DenyFields {
+		r.deny[strings.ToLower(key)] = struct{}{}
+	}
+	return r
+}
+
+// redactRecord redacts the message and the fields of r. The fields are copied
+// before being changed since they are shared with the Logger.
+func (rd *redactor) redactRecord(r *Record) {
+	r.Message = rd.redact(r.Message)
+	var fields []Field
+	for i, field := range r.Fields {
+		var value any
+		var changed bool
+		value, changed = rd.redactField(field)
+		if !changed {
+			continue
+		}
+		if fields == nil {
+			fields = make([]Field, len(r.Fields))
+			copy(fields, r.Fields)
+		}
+		fields[i].Value = value
+	}
+	if fields != nil {
+		r.Fields = fields
+	}
+}
+
+func (rd *redactor) redactField(field Field) (any, bool) {
+	if rd.denied(field.Key) {
+		return rd.replacement, true
+	}
+	var s string
+	switch value := field.Value.(type) {
+	case string:
+		s = value
+	case errorValue:
+		var node errorNode = newErrorNode(value.err, 0)
+		if !rd.redactNode(&node) {
+			return nil, false
+		}
+		return errorNodeValue{node: node}, true
+	case error:
+		s = value.Error()
+	case fmt.Stringer:
+		s = value.String()
+	default:
+		return nil, false
+	}
+	var redacted string = rd.redact(s)
+	return redacted, redacted != s
+}
+
+func (rd *redactor) denied(key string) bool {
+	if len(rd.deny) == 0 {
+		return false
+	}
+	key = strings.ToLower(key)
+	if _, ok := rd.deny[key]; ok {
+		return true
+	}
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		_, ok := rd.deny[key[i+1:]]
+		return ok
+	}
+	return false
+}
+
+// redactNode redacts the messages of an error chain and tells whether any
+// changed.
+func (rd *redactor) redactNode(node *errorNode) bool {
+	var redacted string = rd.redact(node.Message)
+	var changed bool = redacted != node.Message
+	node.Message = redacted
+	if node.Cause != nil && rd.redactNode(node.Cause) {
+		changed = true
+	}
+	for i := range node.Joined {
+		if rd.redactNode(&node.Joined[i]) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// redact returns s with the matches of the rules replaced.
+func (rd *redactor) redact(s string) string {
+	for _, rule := range rd.rules {
+		s = rule.replace(s, rd.replacement)
+	}
+	return s
+}
+
+func (rule redactionRule) replace(s, replacement string) string {
+	var matches [][]int = rule.re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var b strings.Builder
+	var last int
+	for _, match := range matches {
+		var start, end int = match[2*rule.group], match[2*rule.group+1]
+		if start == end || (rule.valid != nil && !rule.valid(s[start:end])) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(replacement)
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// luhnValid tells whether the digits of s pass the Luhn check.
+func luhnValid(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		var digit int = int(s[i] - '0')
+		if n%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+func ipValid(s string) bool {
+	return net.ParseIP(s) != nil
+}
+
+// ipv6Valid tells whether s is an IPv6 address with at least one group of hex
+// digits, "::" alone being more likely a separator.
+func ipv6Valid(s string) bool {
+	return strings.ContainsAny(s, "0123456789abcdefABCDEF") && ipValid(s)
+}
@@ -0,0 +1,229 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const defaultWatchInterval time.Duration = 2 * time.Second
+
+// ApplyConfig replaces the verbosity, the level rules and the destinations of
+// l and of the loggers derived from it by the ones described by c, the default
+// structure and function of c are ignored. The swap is atomic for the
+// concurrent callers: each record is written once, either to the previous
+// destinations or to the new ones, including the records queued by an
+// asynchronous Logger. The files opened by a previous configuration are closed
+// once replaced.
+//
+// The output of c replaces the destination given to NewLogger, while the
+// destinations added by AddSink and WithSink are kept, as are the writers
+// created by NewSyslogLogger, NewJournaldLogger and NewOTLPLogger until Close.
+func (l *Logger) ApplyConfig(c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	var level LogLevel
+	var rules []LevelRule
+	level, rules = c.levels()
+
+	var primary *sink
+	var sinks []*sink
+	var closers []io.Closer
+	var err error
+	if primary, sinks, closers, err = c.openSinks(); err != nil {
+		return err
+	}
+	l.out.replace(level, rules, primary, sinks, closers)
+	return nil
+}
+
+func (o *output) replace(level LogLevel, rules []LevelRule, primary *sink, sinks []*sink, closers []io.Closer) {
+	o.levelMu.Lock()
+	o.mu.Lock()
+	if o.defaultStructure != "" {
+		for _, s := range sinks {
+			s.setDefaultStructure(o.defaultStructure)
+		}
+	}
+	// The primary sink and the sinks of the previous configuration are
+	// replaced, the sinks added by AddSink are kept.
+	var oldSinks []*sink = append([]*sink{o.primary}, o.configSinks...)
+	var kept []*sink
+	for _, s := range o.sinks {
+		if !slices.Contains(oldSinks, s) {
+			kept = append(kept, s)
+		}
+	}
+	var oldClosers []io.Closer = o.configClosers
+	o.sinks = make([]*sink, 0, len(kept)+len(sinks))
+	if primary != nil {
+		o.sinks = append(o.sinks, primary)
+	}
+	o.sinks = append(o.sinks, kept...)
+	o.configSinks = nil
+	for _, s := range sinks {
+		if s != primary {
+			o.configSinks = append(o.configSinks, s)
+		}
+	}
+	o.sinks = append(o.sinks, o.configSinks...)
+	o.primary, o.configClosers = primary, closers
+	o.level, o.rules = level, rules
+	o.buildFuncs()
+	o.mu.Unlock()
+	o.levelMu.Unlock()
+
+	// No record is being written to the previous sinks past this point.
+	for _, s := range oldSinks {
+		if s != nil {
+			s.flush(context.Background())
+		}
+	}
+	for _, closer := range oldClosers {
+		closer.Close()
+	}
+}
+
+// WatchOptions describes how a ConfigWatcher follows its file.
+type WatchOptions struct {
+	// Interval is the period of the polling of the file, 2 seconds if zero. A
+	// negative Interval disables the polling.
+	Interval time.Duration
+	// SIGHUP reloads the file when the process receives SIGHUP.
+	SIGHUP bool
+	// Env applies the LOGGER_ environment variables over the file, as
+	// ConfigFromEnv does.
+	Env bool
+	// OnError is called when a reload fails, the previous configuration is then
+	// kept. The error is logged at the ERROR level if OnError is nil.
+	OnError func(err error)
+}
+
+// ConfigWatcher reloads the configuration of a Logger when its file changes
+// or when the process receives SIGHUP.
+type ConfigWatcher struct {
+	logger   *Logger
+	path     string
+	options  WatchOptions
+	mu       sync.Mutex
+	modTime  time.Time
+	size     int64
+	data     []byte
+	signals  chan os.Signal
+	done     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// WatchConfig applies the configuration file at path to l, see ApplyConfig,
+// then reloads it whenever it changes. The file is polled, which also works
+// for the files replaced by a rename such as the mounted Kubernetes ConfigMaps.
+func (l *Logger) WatchConfig(path string, options WatchOptions) (*ConfigWatcher, error) {
+	if options.Interval == 0 {
+		options.Interval = defaultWatchInterval
+	}
+	var w *ConfigWatcher = &ConfigWatcher{
+		logger:  l,
+		path:    path,
+		options: options,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if w.options.OnError == nil {
+		w.options.OnError = func(err error) {
+			l.LogError("logger", "ConfigWatcher", "cannot reload %s: %v", -1, path, err)
+		}
+	}
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+	if options.SIGHUP {
+		w.signals = make(chan os.Signal, 1)
+		signal.Notify(w.signals, syscall.SIGHUP)
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *ConfigWatcher) run() {
+	defer close(w.stopped)
+	var ticks <-chan time.Time
+	if w.options.Interval > 0 {
+		var ticker *time.Ticker = time.NewTicker(w.options.Interval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+	for {
+		var err error
+		select {
+		case <-ticks:
+			err = w.reload(false)
+		case <-w.signals:
+			err = w.reload(true)
+		case <-w.done:
+			return
+		}
+		if err != nil {
+			w.options.OnError(err)
+		}
+	}
+}
+
+// Reload reads and applies the file, whether it changed or not.
+func (w *ConfigWatcher) Reload() error {
+	return w.reload(true)
+}
+
+// reload applies the file when force is set or when its content changed. An
+// invalid file is not retried until it changes again.
+func (w *ConfigWatcher) reload(force bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var info os.FileInfo
+	var err error
+	if info, err = os.Stat(w.path); err != nil {
+		return err
+	}
+	if !force && info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return nil
+	}
+	var data []byte
+	if data, err = os.ReadFile(w.path); err != nil {
+		return err
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+	if !force && bytes.Equal(data, w.data) {
+		return nil
+	}
+	w.data = data
+
+	var c Config
+	if c, err = ParseConfig(data, configFormat(w.path)); err != nil {
+		return err
+	}
+	if w.options.Env {
+		if err = c.applyEnv(os.LookupEnv); err != nil {
+			return err
+		}
+	}
+	return w.logger.ApplyConfig(c)
+}
+
+// Stop stops watching the file, the current configuration is kept.
+func (w *ConfigWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		if w.signals != nil {
+			signal.Stop(w.signals)
+		}
+		close(w.done)
+	})
+	<-w.stopped
+}
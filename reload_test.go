@@ -0,0 +1,192 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func writeTestConfig(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path+".tmp", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	var deadline time.Time = time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func countLines(path string) int {
+	var data []byte
+	data, _ = os.ReadFile(path)
+	return strings.Count(string(data), "\n")
+}
+
+func TestWatchConfig(t *testing.T) {
+	var dir string = t.TempDir()
+	var path string = filepath.Join(dir, "logger.yaml")
+	var first, second string = filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+	writeTestConfig(t, path, "level: INFO\noutput: "+first+"\n")
+
+	var logger *Logger = NewLogger(LogLevelError, nil, WithAsync(AsyncConfig{QueueSize: 16}))
+	var watcher *ConfigWatcher
+	var err error
+	if watcher, err = logger.WatchConfig(path, WatchOptions{Interval: 10 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if logger.Verbosity() != LogLevelInfo {
+		t.Errorf("the configuration must be applied at once, got %s", GetLevelName(logger.Verbosity()))
+	}
+
+	var wg sync.WaitGroup
+	var stop chan struct{} = make(chan struct{})
+	var counts [4]int
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var child *Logger = logger.With("worker", i)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				child.LogInfo("struct", "function", "record %d", -1, counts[i])
+				counts[i]++
+			}
+		}(i)
+	}
+
+	waitFor(t, func() bool { return countLines(first) > 0 })
+	writeTestConfig(t, path, "level: DEBUG\nrules: [\"quiet=ERROR\"]\noutput: "+second+"\nformat: json\n")
+	waitFor(t, func() bool { return logger.Verbosity() == LogLevelDebug })
+	close(stop)
+	wg.Wait()
+	watcher.Stop()
+
+	logger.LogDebug("struct", "function", "debug", -1)
+	logger.LogInfo("quiet", "function", "info", -1)
+	if err = logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var total int = 1
+	for _, count := range counts {
+		total += count
+	}
+	if got := countLines(first) + countLines(second); got != total {
+		t.Errorf("every record must be written once, got %d lines for %d records", got, total)
+	}
+	var data []byte
+	data, _ = os.ReadFile(second)
+	if !strings.HasSuffix(string(data), "\"msg\":\"debug\"}\n") {
+		t.Errorf("the new level, rules and format must be used, got %q", data)
+	}
+}
+
+func TestWatchConfigErrorsAndSIGHUP(t *testing.T) {
+	var dir string = t.TempDir()
+	var path string = filepath.Join(dir, "logger.toml")
+	writeTestConfig(t, path, "level = \"WARNING\"\noutput = \"none\"\n")
+
+	var process *os.Process
+	var err error
+	if process, err = os.FindProcess(os.Getpid()); err != nil || process.Signal(syscall.Signal(0)) != nil {
+		t.Skip("unable to signal the process")
+	}
+
+	var logger *Logger = NewLogger(LogLevelError, nil)
+	var errs chan error = make(chan error, 1)
+	var watcher *ConfigWatcher
+	if watcher, err = logger.WatchConfig(path, WatchOptions{Interval: -1, SIGHUP: true, OnError: func(err error) { errs <- err }}); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	writeTestConfig(t, path, "level = \"LOUD\"\n")
+	process.Signal(syscall.SIGHUP)
+	select {
+	case err = <-errs:
+		if !strings.Contains(err.Error(), "level") {
+			t.Errorf("the error must name the key, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the invalid configuration must be reported")
+	}
+	if logger.Verbosity() != LogLevelWarning {
+		t.Errorf("the previous configuration must be kept, got %s", GetLevelName(logger.Verbosity()))
+	}
+
+	writeTestConfig(t, path, "level = \"TRACE\"\noutput = \"none\"\n")
+	process.Signal(syscall.SIGHUP)
+	waitFor(t, func() bool { return logger.Verbosity() == LogLevelTrace })
+
+	if _, err = logger.WatchConfig(filepath.Join(dir, "missing.toml"), WatchOptions{}); err == nil {
+		t.Error("a missing file must be reported")
+	}
+}
+
+type countingCloser struct {
+	closed int
+}
+
+func (c *countingCloser) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestApplyConfigKeepsAddedSinks(t *testing.T) {
+	var dir string = t.TempDir()
+	var primary, added *bytes.Buffer = bytes.NewBuffer([]byte{}), bytes.NewBuffer([]byte{})
+	var closer *countingCloser = new(countingCloser)
+	var logger *Logger = NewLogger(LogLevelInfo, primary, WithSink(added, LogLevelTrace, nil))
+	logger.out.closers = append(logger.out.closers, closer)
+
+	var first string = filepath.Join(dir, "first.log")
+	if err := logger.ApplyConfig(Config{OutputConfig: OutputConfig{Output: first}}); err != nil {
+		t.Fatal(err)
+	}
+	logger.LogInfo("struct", "function", "first", -1)
+	var second string = filepath.Join(dir, "second.log")
+	if err := logger.ApplyConfig(Config{OutputConfig: OutputConfig{Output: configOutputNone}, Sinks: []SinkConfig{{OutputConfig: OutputConfig{Output: second}}}}); err != nil {
+		t.Fatal(err)
+	}
+	logger.LogInfo("struct", "function", "second", -1)
+
+	if primary.Len() != 0 {
+		t.Errorf("the output of the config must replace the destination of NewLogger, got %q", primary.String())
+	}
+	if !strings.Contains(added.String(), "first") || !strings.Contains(added.String(), "second") {
+		t.Errorf("the sinks added in code must survive the reloads, got %q", added.String())
+	}
+	if countLines(first) != 1 || countLines(second) != 1 {
+		t.Errorf("expected one record per configured file, got %d and %d", countLines(first), countLines(second))
+	}
+	if closer.closed != 0 {
+		t.Error("the writers registered in code must not be closed by ApplyConfig")
+	}
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if closer.closed != 1 {
+		t.Errorf("the writers registered in code must be closed once by Close, got %d", closer.closed)
+	}
+}
@@ -0,0 +1,352 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type RotationInterval uint8
+
+const (
+	RotateNever RotationInterval = iota
+	RotateHourly
+	RotateDaily
+)
+
+const (
+	rotateBackupTimeLayout string = "2006-01-02T15-04-05.000"
+	rotateCompressSuffix   string = ".gz"
+)
+
+// RotatingFileConfig describes a RotatingFile. A zero MaxSize, Interval,
+// MaxBackups or MaxAge disables the matching rotation or cleanup.
+type RotatingFileConfig struct {
+	Filename string
+	// MaxSize is the size in bytes above which the file is rotated.
+	MaxSize  int64
+	Interval RotationInterval
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups int
+	// MaxAge is the duration a rotated file is kept.
+	MaxAge time.Duration
+	// Compress gzips the rotated files.
+	Compress bool
+	// ReopenOnSIGHUP reopens the file when the process receives SIGHUP, for
+	// use with an external logrotate moving the file away.
+	ReopenOnSIGHUP bool
+}
+
+// RotatingFile is an io.Writer writing to a file rotated on size and time.
+// The rotated files are renamed with their rotation time between the name and
+// the extension of the file, for instance app-2023-05-17T08-04-02.000.log.
+// It is safe for concurrent use.
+type RotatingFile struct {
+	mu           sync.Mutex
+	config       RotatingFileConfig
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+	signals      chan os.Signal
+	done         chan struct{}
+	cleanupMu    sync.Mutex
+	cleanupWg    sync.WaitGroup
+	now          func() time.Time
+	rename       func(oldpath, newpath string) error
+}
+
+func NewRotatingFile(config RotatingFileConfig) (*RotatingFile, error) {
+	if config.Filename == "" {
+		return nil, errors.New("logger: the filename of a rotating file cannot be empty")
+	}
+	var w *RotatingFile = &RotatingFile{config: config, now: time.Now, rename: os.Rename}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	if config.ReopenOnSIGHUP {
+		w.signals = make(chan os.Signal, 1)
+		w.done = make(chan struct{})
+		signal.Notify(w.signals, syscall.SIGHUP)
+		go w.watchSignals()
+	}
+	return w, nil
+}
+
+func (w *RotatingFile) watchSignals() {
+	for {
+		select {
+		case <-w.signals:
+			w.Reopen()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *RotatingFile) open() error {
+	var file *os.File
+	var size int64
+	var err error
+	if file, size, err = w.openFile(); err != nil {
+		return err
+	}
+	w.file, w.size = file, size
+	w.nextRotation = w.rotationAfter(w.now())
+	return nil
+}
+
+// openFile opens the file for appending and returns its size.
+func (w *RotatingFile) openFile() (*os.File, int64, error) {
+	var file *os.File
+	var err error
+	if err = os.MkdirAll(filepath.Dir(w.config.Filename), 0755); err != nil {
+		return nil, 0, err
+	}
+	if file, err = os.OpenFile(w.config.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return nil, 0, err
+	}
+	var info os.FileInfo
+	if info, err = file.Stat(); err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+func (w *RotatingFile) rotationAfter(t time.Time) time.Time {
+	switch w.config.Interval {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()).Add(time.Hour)
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+func (w *RotatingFile) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	var now time.Time = w.now()
+	var sizeExceeded bool = w.config.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.config.MaxSize
+	var timeExceeded bool = !w.nextRotation.IsZero() && !now.Before(w.nextRotation)
+	// A failed rotation is retried by the next writes, the records keep going
+	// to the current file meanwhile.
+	if sizeExceeded || timeExceeded {
+		if err := w.rotate(now); err != nil && w.file == nil {
+			return 0, err
+		}
+	}
+
+	var n int
+	var err error
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate forces the rotation of the file.
+func (w *RotatingFile) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.rotate(w.now())
+}
+
+// rotate moves the current file to a backup and opens a new one. On failure
+// the current file is reopened so that the writes go on.
+func (w *RotatingFile) rotate(now time.Time) error {
+	var backup string = w.backupName(now)
+	// Several rotations may happen within the same millisecond.
+	for fileExists(backup) || fileExists(backup+rotateCompressSuffix) {
+		now = now.Add(time.Millisecond)
+		backup = w.backupName(now)
+	}
+	// The file is closed before being renamed, which Windows requires.
+	var closeErr error = w.file.Close()
+	var err error
+	if err = w.rename(w.config.Filename, backup); err != nil && !os.IsNotExist(err) {
+		return w.reopen(err)
+	}
+	var file *os.File
+	var size int64
+	if file, size, err = w.openFile(); err != nil {
+		w.rename(backup, w.config.Filename)
+		return w.reopen(err)
+	}
+	w.file, w.size = file, size
+	w.nextRotation = w.rotationAfter(w.now())
+	w.cleanupWg.Add(1)
+	go w.cleanup()
+	return closeErr
+}
+
+// reopen reopens the current file after a failed rotation and returns err.
+// The file is left closed only if it cannot be reopened either. The time of
+// the next rotation is kept so that a time based rotation is retried by the
+// next write instead of an interval later.
+func (w *RotatingFile) reopen(err error) error {
+	var nextRotation time.Time = w.nextRotation
+	w.file = nil
+	w.open()
+	w.nextRotation = nextRotation
+	return err
+}
+
+// Sync commits the content of the current file to stable storage.
+func (w *RotatingFile) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.file.Sync()
+}
+
+// Reopen closes and reopens the file without rotating it, to follow a file
+// moved away by an external tool such as logrotate.
+func (w *RotatingFile) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	var file *os.File
+	var size int64
+	var err error
+	if file, size, err = w.openFile(); err != nil {
+		return err
+	}
+	w.file.Close()
+	w.file, w.size = file, size
+	return nil
+}
+
+func (w *RotatingFile) Close() error {
+	w.mu.Lock()
+	if w.done != nil {
+		signal.Stop(w.signals)
+		close(w.done)
+		w.done = nil
+	}
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+	w.cleanupWg.Wait()
+	return err
+}
+
+func (w *RotatingFile) backupName(t time.Time) string {
+	var ext string = filepath.Ext(w.config.Filename)
+	var prefix string = strings.TrimSuffix(w.config.Filename, ext)
+	return prefix + "-" + t.Format(rotateBackupTimeLayout) + ext
+}
+
+type rotatedFile struct {
+	path string
+	time time.Time
+}
+
+func (w *RotatingFile) backups() ([]rotatedFile, error) {
+	var dir string = filepath.Dir(w.config.Filename)
+	var ext string = filepath.Ext(w.config.Filename)
+	var prefix string = strings.TrimSuffix(filepath.Base(w.config.Filename), ext) + "-"
+
+	var entries []os.DirEntry
+	var err error
+	if entries, err = os.ReadDir(dir); err != nil {
+		return nil, err
+	}
+	var files []rotatedFile
+	for _, entry := range entries {
+		var name string = strings.TrimSuffix(entry.Name(), rotateCompressSuffix)
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		var t time.Time
+		if t, err = time.ParseInLocation(rotateBackupTimeLayout, name[len(prefix):len(name)-len(ext)], time.Local); err != nil {
+			continue
+		}
+		files = append(files, rotatedFile{path: filepath.Join(dir, entry.Name()), time: t})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].time.After(files[j].time)
+	})
+	return files, nil
+}
+
+// cleanup removes the rotated files exceeding MaxBackups or MaxAge and
+// compresses the remaining ones. It runs in its own goroutine so the writers
+// are not blocked by the compression.
+func (w *RotatingFile) cleanup() {
+	defer w.cleanupWg.Done()
+	w.cleanupMu.Lock()
+	defer w.cleanupMu.Unlock()
+
+	var files []rotatedFile
+	var err error
+	if files, err = w.backups(); err != nil {
+		return
+	}
+	var cutoff time.Time
+	if w.config.MaxAge > 0 {
+		cutoff = w.now().Add(-w.config.MaxAge)
+	}
+	for i, file := range files {
+		if (w.config.MaxBackups > 0 && i >= w.config.MaxBackups) || (!cutoff.IsZero() && file.time.Before(cutoff)) {
+			os.Remove(file.path)
+			continue
+		}
+		if w.config.Compress && !strings.HasSuffix(file.path, rotateCompressSuffix) {
+			compressFile(file.path)
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	var err error
+	_, err = os.Lstat(path)
+	return err == nil
+}
+
+func compressFile(path string) error {
+	var src, dst *os.File
+	var err error
+	if src, err = os.Open(path); err != nil {
+		return err
+	}
+	defer src.Close()
+	if dst, err = os.OpenFile(path+rotateCompressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644); err != nil {
+		return err
+	}
+
+	var gz *gzip.Writer = gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + rotateCompressSuffix)
+		return err
+	}
+	return os.Remove(path)
+}
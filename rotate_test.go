@@ -0,0 +1,201 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listDir(t *testing.T, dir string) []string {
+	var entries []os.DirEntry
+	var err error
+	if entries, err = os.ReadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	var dir string = t.TempDir()
+	var w *RotatingFile
+	var err error
+	if w, err = NewRotatingFile(RotatingFileConfig{Filename: filepath.Join(dir, "app.log"), MaxSize: 100, MaxBackups: 2}); err != nil {
+		t.Fatal(err)
+	}
+	var logger *Logger = NewLogger(LogLevelInfo, w)
+	for i := 0; i < 10; i++ {
+		logger.LogInfo("struct", "function", "message number %d", i, i)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string = listDir(t, dir)
+	if len(names) != 3 {
+		t.Fatalf("the current file and 2 backups expected, got %v", names)
+	}
+	for _, name := range names {
+		if name != "app.log" && (!strings.HasPrefix(name, "app-") || !strings.HasSuffix(name, ".log")) {
+			t.Errorf("unexpected file name %s", name)
+		}
+	}
+	var content []byte
+	if content, err = os.ReadFile(filepath.Join(dir, "app.log")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(content), "message number 9\n") {
+		t.Errorf("the last message must be in the current file, got %q", content)
+	}
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	var dir string = t.TempDir()
+	var now time.Time = time.Date(2023, 5, 17, 23, 59, 0, 0, time.Local)
+	var w *RotatingFile = &RotatingFile{config: RotatingFileConfig{Filename: filepath.Join(dir, "app.log"), Interval: RotateDaily, Compress: true}, rename: os.Rename}
+	w.now = func() time.Time { return now }
+	if err := w.open(); err != nil {
+		t.Fatal(err)
+	}
+
+	w.Write([]byte("day one\n"))
+	now = now.Add(2 * time.Minute)
+	w.Write([]byte("day two\n"))
+	w.Close()
+
+	var backup string = filepath.Join(dir, "app-2023-05-18T00-01-00.000.log.gz")
+	var f *os.File
+	var err error
+	if f, err = os.Open(backup); err != nil {
+		t.Fatalf("compressed backup expected, got %v", listDir(t, dir))
+	}
+	defer f.Close()
+	var gz *gzip.Reader
+	if gz, err = gzip.NewReader(f); err != nil {
+		t.Fatal(err)
+	}
+	var content []byte
+	if content, err = io.ReadAll(gz); err != nil || string(content) != "day one\n" {
+		t.Errorf("incorrect backup content %q: %v", content, err)
+	}
+	if content, _ = os.ReadFile(filepath.Join(dir, "app.log")); string(content) != "day two\n" {
+		t.Errorf("incorrect current content %q", content)
+	}
+}
+
+func TestRotatingFileMaxAge(t *testing.T) {
+	var dir string = t.TempDir()
+	var old string = filepath.Join(dir, "app-2000-01-01T00-00-00.000.log")
+	if err := os.WriteFile(old, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var w *RotatingFile
+	var err error
+	if w, err = NewRotatingFile(RotatingFileConfig{Filename: filepath.Join(dir, "app.log"), MaxAge: 24 * time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("new\n"))
+	w.Rotate()
+	w.Close()
+
+	if _, err = os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("the backup older than MaxAge must be removed, got %v", listDir(t, dir))
+	}
+	if len(listDir(t, dir)) != 2 {
+		t.Errorf("the current file and the recent backup expected, got %v", listDir(t, dir))
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	var dir string = t.TempDir()
+	var path string = filepath.Join(dir, "app.log")
+	var w *RotatingFile
+	var err error
+	if w, err = NewRotatingFile(RotatingFileConfig{Filename: path}); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("before\n"))
+	if err = os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("after\n"))
+
+	var content []byte
+	if content, _ = os.ReadFile(path); string(content) != "after\n" {
+		t.Errorf("incorrect content after reopening %q", content)
+	}
+	if content, _ = os.ReadFile(path + ".1"); string(content) != "before\n" {
+		t.Errorf("incorrect content of the moved file %q", content)
+	}
+}
+
+func TestRotatingFileRenameFailure(t *testing.T) {
+	var dir string = t.TempDir()
+	var path string = filepath.Join(dir, "app.log")
+	var w *RotatingFile
+	var err error
+	if w, err = NewRotatingFile(RotatingFileConfig{Filename: path, MaxSize: 10}); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	var renameErr error = &os.LinkError{Op: "rename", Err: os.ErrPermission}
+	w.rename = func(string, string) error { return renameErr }
+
+	w.Write([]byte("first\n"))
+	if err = w.Rotate(); err != renameErr {
+		t.Errorf("the rename error must be returned, got %v", err)
+	}
+	if _, err = w.Write([]byte("second line\n")); err != nil {
+		t.Errorf("the writes must go on after a failed rotation, got %v", err)
+	}
+	var content []byte
+	if content, _ = os.ReadFile(path); string(content) != "first\nsecond line\n" {
+		t.Errorf("the records must be kept in the current file, got %q", content)
+	}
+
+	w.rename = os.Rename
+	if _, err = w.Write([]byte("third\n")); err != nil {
+		t.Fatal(err)
+	}
+	if names := listDir(t, dir); len(names) != 2 {
+		t.Errorf("the rotation must be retried, got %v", names)
+	}
+	if content, _ = os.ReadFile(path); string(content) != "third\n" {
+		t.Errorf("incorrect content after the rotation %q", content)
+	}
+
+	dir = t.TempDir()
+	path = filepath.Join(dir, "app.log")
+	var now time.Time = time.Date(2023, 5, 17, 23, 59, 0, 0, time.Local)
+	var daily *RotatingFile = &RotatingFile{config: RotatingFileConfig{Filename: path, Interval: RotateDaily}, rename: os.Rename}
+	daily.now = func() time.Time { return now }
+	if err = daily.open(); err != nil {
+		t.Fatal(err)
+	}
+	defer daily.Close()
+	daily.Write([]byte("day one\n"))
+	now = now.Add(2 * time.Minute)
+	daily.rename = func(string, string) error { return renameErr }
+	daily.Write([]byte("day two\n"))
+	daily.rename = os.Rename
+	now = now.Add(time.Minute)
+	daily.Write([]byte("day two again\n"))
+	if names := listDir(t, dir); len(names) != 2 {
+		t.Errorf("the time based rotation must be retried by the next write, got %v", names)
+	}
+	if content, _ = os.ReadFile(path); string(content) != "day two again\n" {
+		t.Errorf("incorrect content after the daily rotation %q", content)
+	}
+}
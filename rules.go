@@ -0,0 +1,134 @@
+package logger
+
+// levelCacheSize bounds the number of structure and function pairs whose
+// level is cached, the level of the other pairs is computed on each call.
+const levelCacheSize int = 1024
+
+// LevelRule sets the verbosity of the records whose structure, or structure
+// and function joined by a dot, match Pattern. In Pattern, * matches any
+// sequence of characters, dots included: "db.*" matches the structure db.pool
+// and the function Query of the structure db, "http.Server" matches the
+// structure http.Server and the function Server of the structure http.
+type LevelRule struct {
+	Pattern string
+	Level   LogLevel
+}
+
+func (r LevelRule) match(structure, function string) bool {
+	return globMatch(r.Pattern, structure) || globMatch(r.Pattern, structure+"."+function)
+}
+
+// SetLevelRules replaces the level rules of l and of the loggers derived from
+// it. The first rule matching a record gives its verbosity, the one set by
+// SetVerbosity is used when none matches. The decisions are cached per
+// structure and function, the disabled levels cost a map lookup.
+func (l *Logger) SetLevelRules(rules ...LevelRule) {
+	l.out.setLevelRules(rules)
+}
+
+// LevelRules returns a copy of the level rules of l.
+func (l *Logger) LevelRules() []LevelRule {
+	return append([]LevelRule(nil), l.out.funcs.Load().rules...)
+}
+
+// WithLevelRules sets the level rules of the Logger, see SetLevelRules.
+func WithLevelRules(rules ...LevelRule) Option {
+	return func(l *Logger) {
+		l.out.levelMu.Lock()
+		l.out.rules = append([]LevelRule(nil), rules...)
+		l.out.levelMu.Unlock()
+	}
+}
+
+func (o *output) setLevelRules(rules []LevelRule) {
+	o.levelMu.Lock()
+	defer o.levelMu.Unlock()
+	o.rules = append([]LevelRule(nil), rules...)
+	o.buildFuncs()
+}
+
+func newLogFuncs(level LogLevel, rules []LevelRule) *logFuncs {
+	var f *logFuncs = &logFuncs{level: level, rules: rules, maxLevel: level}
+	for _, rule := range rules {
+		if rule.Level > f.maxLevel {
+			f.maxLevel = rule.Level
+		}
+	}
+	return f
+}
+
+type levelKey struct {
+	structure string
+	function  string
+}
+
+// enabled reports whether a record of the given level, structure and function
+// is allowed by the verbosity and the level rules.
+func (f *logFuncs) enabled(level LogLevel, structure, function string) bool {
+	if level > f.maxLevel {
+		return false
+	}
+	return len(f.rules) == 0 || level <= f.levelOf(structure, function)
+}
+
+// levelOf returns the verbosity of a structure and function. The cache is a
+// map replaced on each miss so that the hits, by far the most frequent, are
+// lock free.
+func (f *logFuncs) levelOf(structure, function string) LogLevel {
+	var key levelKey = levelKey{structure: structure, function: function}
+	var cache map[levelKey]LogLevel
+	if p := f.cache.Load(); p != nil {
+		cache = *p
+		if level, ok := cache[key]; ok {
+			return level
+		}
+	}
+	var level LogLevel = f.level
+	for _, rule := range f.rules {
+		if rule.match(structure, function) {
+			level = rule.Level
+			break
+		}
+	}
+
+	f.cacheMu.Lock()
+	defer f.cacheMu.Unlock()
+	if p := f.cache.Load(); p != nil {
+		cache = *p
+	}
+	if len(cache) < levelCacheSize {
+		var updated map[levelKey]LogLevel = make(map[levelKey]LogLevel, len(cache)+1)
+		for k, v := range cache {
+			updated[k] = v
+		}
+		updated[key] = level
+		f.cache.Store(&updated)
+	}
+	return level
+}
+
+// globMatch reports whether s matches pattern, where * matches any sequence of
+// characters.
+func globMatch(pattern, s string) bool {
+	var star, next int = -1, 0
+	var p, i int
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, next = p, i
+			p++
+		case p < len(pattern) && pattern[p] == s[i]:
+			p++
+			i++
+		case star >= 0:
+			next++
+			p, i = star+1, next
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGlobMatch(t *testing.T) {
+	var tests = []struct {
+		pattern string
+		s       string
+		match   bool
+	}{
+		{"*", "", true},
+		{"*", "anything", true},
+		{"db.*", "db.Query", true},
+		{"db.*", "db", false},
+		{"db.*", "dbx.Query", false},
+		{"*.Query", "db.pool.Query", true},
+		{"http.Server", "http.Server", true},
+		{"http.Server", "http.ServerX", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+	}
+	for _, test := range tests {
+		if globMatch(test.pattern, test.s) != test.match {
+			t.Errorf("globMatch(%q, %q) must return %t", test.pattern, test.s, test.match)
+		}
+	}
+}
+
+func TestLevelRules(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelError, buffer, WithLevelRules(
+		LevelRule{Pattern: "db.*", Level: LogLevelDebug},
+		LevelRule{Pattern: "http.Server", Level: LogLevelWarning},
+		LevelRule{Pattern: "*", Level: LogLevelInfo},
+	))
+
+	logger.LogDebug("db", "Query", "db debug", -1)
+	logger.LogTrace("db", "Query", "db trace", -1)
+	logger.LogNotice("http", "Server", "http notice", -1)
+	logger.LogWarning("http.Server", "Serve", "http warning", -1)
+	logger.LogInfo("cache", "Get", "cache info", -1)
+	logger.LogDebug("cache", "Get", "cache debug", -1)
+	logger.LogDebugCtx(context.Background(), "db", "Exec", "db debug ctx", -1)
+
+	var output string = buffer.String()
+	for _, expected := range []string{"db debug", "http warning", "cache info", "db debug ctx"} {
+		if !strings.Contains(output, expected+"\n") {
+			t.Errorf("expected %q in %q", expected, output)
+		}
+	}
+	for _, unexpected := range []string{"db trace", "http notice", "cache debug"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("unexpected %q in %q", unexpected, output)
+		}
+	}
+
+	buffer.Reset()
+	logger.SetLevelRules(LevelRule{Pattern: "cache", Level: LogLevelTrace})
+	logger.LogTrace("cache", "Get", "cache trace", -1)
+	logger.LogDebug("db", "Query", "db debug", -1)
+	if buffer.String() == "" || !strings.HasSuffix(buffer.String(), "cache -> Get: cache trace\n") {
+		t.Errorf("the new rules must replace the old ones, got %q", buffer.String())
+	}
+	if len(logger.LevelRules()) != 1 || logger.Verbosity() != LogLevelError {
+		t.Errorf("unexpected rules %v or verbosity %s", logger.LevelRules(), GetLevelName(logger.Verbosity()))
+	}
+}
+
+func BenchmarkLogLevelRulesDisabled(b *testing.B) {
+	var logger *Logger = NewLogger(LogLevelInfo, io.Discard, WithLevelRules(LevelRule{Pattern: "db.*", Level: LogLevelDebug}))
+	for i := 0; i < b.N; i++ {
+		logger.LogDebug("http", "Serve", "message", -1)
+	}
+}
@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	samplingDefaultInterval time.Duration = time.Second
+	samplingMaxKeys         int           = 4096
+	samplingStructure       string        = "logger"
+	samplingFunction        string        = "sampling"
+)
+
+// SamplingConfig describes the sampling of the records. The records are
+// counted per level, or per call site with PerKey, over periods of Interval:
+// the First records of a period are written, then every Thereafter-th one, or
+// when Thereafter is zero each one with the probability Rate. A config with
+// only Rate set samples every record with that probability, and a config
+// without First, Thereafter and Rate writes every record.
+type SamplingConfig struct {
+	// Level is the most severe level sampled, for instance LogLevelInfo for
+	// the INFO, DEBUG and TRACE records [LogLevelDebug]. The records of the
+	// FatalXxx methods are never sampled.
+	Level LogLevel
+	// Interval is the period over which the records are counted [1s].
+	Interval time.Duration
+	// First is the number of records written at the start of each period.
+	First int
+	// Thereafter writes every Thereafter-th record after the First ones.
+	Thereafter int
+	// Rate is the probability, between 0 and 1, of writing a record after the
+	// First ones when Thereafter is zero.
+	Rate float64
+	// PerKey counts the records per structure, function and message template
+	// instead of per level, so that a hot call site does not starve the others.
+	PerKey bool
+	// SummaryInterval is the period of the summary line telling how many
+	// records were suppressed, logged at the INFO level. Zero disables it.
+	SummaryInterval time.Duration
+}
+
+// WithSampling samples the records at or below config.Level. Close stops the
+// summary and logs the records suppressed since the last one.
+func WithSampling(config SamplingConfig) Option {
+	return func(l *Logger) {
+		if l.out.sampler != nil {
+			l.out.sampler.stop()
+			l.out.sampler = nil
+		}
+		if config.First > 0 || config.Thereafter > 0 || config.Rate > 0 {
+			l.out.sampler = newSampler(config, l.out)
+		}
+	}
+}
+
+// Suppressed returns the number of records suppressed by the sampling.
+func (l *Logger) Suppressed() uint64 {
+	if l.out.sampler == nil {
+		return 0
+	}
+	return l.out.sampler.suppressed.Load()
+}
+
+// recordKey identifies the records counted together, by level or by call site.
+type recordKey struct {
+	level     LogLevel
+	structure string
+	function  string
+	msg       string
+}
+
+type samplingCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+type sampler struct {
+	config     SamplingConfig
+	out        *output
+	counters   sync.Map
+	keys       atomic.Int64
+	suppressed atomic.Uint64
+	pending    atomic.Uint64
+	done       chan struct{}
+	stopped    chan struct{}
+	stopOnce   sync.Once
+}
+
+func newSampler(config SamplingConfig, o *output) *sampler {
+	if config.Level == LogLevelNull {
+		config.Level = LogLevelDebug
+	}
+	if config.Interval <= 0 {
+		config.Interval = samplingDefaultInterval
+	}
+	var s *sampler = &sampler{config: config, out: o}
+	if config.SummaryInterval > 0 {
+		s.done = make(chan struct{})
+		s.stopped = make(chan struct{})
+		go s.run()
+	}
+	return s
+}
+
+// drop tells whether the record is suppressed and counts it.
+func (s *sampler) drop(level LogLevel, structure, function, msg string) bool {
+	if level < s.config.Level {
+		return false
+	}
+	var key recordKey = recordKey{level: level}
+	if s.config.PerKey && s.keys.Load() < int64(samplingMaxKeys) {
+		key.structure, key.function, key.msg = structure, function, msg
+	}
+	if s.sample(s.counter(key).inc(time.Now().UnixNano(), int64(s.config.Interval))) {
+		return false
+	}
+	s.suppressed.Add(1)
+	s.pending.Add(1)
+	return true
+}
+
+// sample tells whether the n-th record of the period is written.
+func (s *sampler) sample(n uint64) bool {
+	if n <= uint64(s.config.First) {
+		return true
+	}
+	if s.config.Thereafter > 0 {
+		return (n-uint64(s.config.First))%uint64(s.config.Thereafter) == 0
+	}
+	return s.config.Rate > 0 && rand.Float64() < s.config.Rate
+}
+
+func (s *sampler) counter(key recordKey) *samplingCounter {
+	if c, ok := s.counters.Load(key); ok {
+		return c.(*samplingCounter)
+	}
+	var c any
+	var loaded bool
+	c, loaded = s.counters.LoadOrStore(key, new(samplingCounter))
+	if !loaded {
+		s.keys.Add(1)
+	}
+	return c.(*samplingCounter)
+}
+
+// inc counts a record logged at now and returns its rank in the period.
+func (c *samplingCounter) inc(now, interval int64) uint64 {
+	var resetAt int64 = c.resetAt.Load()
+	if now >= resetAt && c.resetAt.CompareAndSwap(resetAt, now+interval) {
+		c.count.Store(1)
+		return 1
+	}
+	return c.count.Add(1)
+}
+
+func (s *sampler) run() {
+	var ticker *time.Ticker = time.NewTicker(s.config.SummaryInterval)
+	defer ticker.Stop()
+	defer close(s.stopped)
+	for {
+		select {
+		case <-ticker.C:
+			s.summarize()
+		case <-s.done:
+			s.summarize()
+			return
+		}
+	}
+}
+
+// summarize logs the number of records suppressed since the last summary.
+func (s *sampler) summarize() {
+	var n uint64 = s.pending.Swap(0)
+	if n == 0 {
+		return
+	}
+	var r Record = Record{
+		Time:      time.Now(),
+		Level:     LogLevelInfo,
+		Structure: samplingStructure,
+		Function:  samplingFunction,
+		Id:        -1,
+		Message:   strconv.FormatUint(n, 10) + " records suppressed by sampling",
+		Fields:    []Field{{Key: "suppressed", Value: n}},
+	}
+	s.out.write(&r)
+}
+
+// stop stops the summary after logging the pending count.
+func (s *sampler) stop() {
+	if s.done == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.done)
+		<-s.stopped
+	})
+}
@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSamplingFirstThereafter(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithSampling(SamplingConfig{Level: LogLevelDebug, Interval: time.Hour, First: 3, Thereafter: 5}))
+
+	for i := 0; i < 20; i++ {
+		logger.LogDebug("struct", "function", "debug %d", -1, i)
+		logger.LogInfo("struct", "function", "info %d", -1, i)
+	}
+	var output string = buffer.String()
+	for _, expected := range []string{"debug 0\n", "debug 1\n", "debug 2\n", "debug 7\n", "debug 12\n", "debug 17\n"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q in %q", expected, output)
+		}
+	}
+	if n := strings.Count(output, "debug "); n != 6 {
+		t.Errorf("expected 6 debug records, got %d", n)
+	}
+	if n := strings.Count(output, "info "); n != 20 {
+		t.Errorf("the records above the sampled level must not be sampled, got %d", n)
+	}
+	if logger.Suppressed() != 14 {
+		t.Errorf("expected 14 suppressed records, got %d", logger.Suppressed())
+	}
+}
+
+func TestSamplingPerKey(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithSampling(SamplingConfig{Interval: time.Hour, First: 2, PerKey: true}))
+
+	for i := 0; i < 10; i++ {
+		logger.LogDebug("struct", "hot", "hot %d", -1, i)
+	}
+	logger.LogDebug("struct", "cold", "cold %d", -1, 0)
+	logger.LogDebug("struct", "hot", "other %d", -1, 0)
+	if n := strings.Count(buffer.String(), "\n"); n != 4 || !strings.Contains(buffer.String(), "cold 0") || !strings.Contains(buffer.String(), "other 0") {
+		t.Errorf("each call site must have its own counter, got %q", buffer.String())
+	}
+}
+
+func TestSamplingInterval(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithSampling(SamplingConfig{Level: LogLevelInfo, Interval: 20 * time.Millisecond, First: 1}))
+
+	logger.LogInfo("struct", "function", "first", -1)
+	logger.LogInfo("struct", "function", "second", -1)
+	time.Sleep(30 * time.Millisecond)
+	logger.LogInfo("struct", "function", "third", -1)
+	if strings.Contains(buffer.String(), "second") || !strings.Contains(buffer.String(), "third") {
+		t.Errorf("the counter must be reset every interval, got %q", buffer.String())
+	}
+}
+
+func TestSamplingDefaults(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithSampling(SamplingConfig{}))
+	for i := 0; i < 5; i++ {
+		logger.LogDebug("struct", "function", "debug", -1)
+	}
+	if strings.Count(buffer.String(), "\n") != 5 || logger.Suppressed() != 0 {
+		t.Errorf("a config without policy must write every record, got %q", buffer.String())
+	}
+
+	buffer.Reset()
+	logger = NewLogger(LogLevelTrace, buffer, WithSampling(SamplingConfig{First: 1}))
+	for i := 0; i < 5; i++ {
+		logger.LogEmerge("struct", "function", "emerge", -1)
+		logger.LogInfo("struct", "function", "info", -1)
+		logger.LogTrace("struct", "function", "trace", -1)
+	}
+	if strings.Count(buffer.String(), "emerge") != 5 || strings.Count(buffer.String(), "info") != 5 || strings.Count(buffer.String(), "trace") != 1 {
+		t.Errorf("only the DEBUG and TRACE records must be sampled by default, got %q", buffer.String())
+	}
+}
+
+func TestSamplingRate(t *testing.T) {
+	var logger *Logger = NewLogger(LogLevelTrace, nil, WithSampling(SamplingConfig{Rate: 0.25}))
+	for i := 0; i < 4000; i++ {
+		logger.LogTrace("struct", "function", "message", -1)
+	}
+	if n := logger.Suppressed(); n < 2700 || n > 3300 {
+		t.Errorf("expected about 3000 suppressed records, got %d", n)
+	}
+
+	logger = NewLogger(LogLevelTrace, nil, WithSampling(SamplingConfig{Rate: 0.25}), WithExitFunc(func(int) {}))
+	for i := 0; i < 100; i++ {
+		logger.FatalTrace("struct", "function", "message", -1)
+	}
+	if logger.Suppressed() != 0 {
+		t.Error("the fatal records must not be sampled")
+	}
+}
+
+func TestSamplingSummary(t *testing.T) {
+	var receiver *gatedWriter = newGatedWriter()
+	close(receiver.gate)
+	var logger *Logger = NewLogger(LogLevelTrace, receiver, WithFormatter(new(LogfmtFormatter)), WithSampling(SamplingConfig{Interval: time.Hour, First: 1, SummaryInterval: 10 * time.Millisecond}))
+
+	for i := 0; i < 5; i++ {
+		logger.LogDebug("struct", "function", "message", -1)
+	}
+	var deadline time.Time = time.Now().Add(2 * time.Second)
+	for !strings.Contains(receiver.String(), "suppressed=4") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.Contains(receiver.String(), `msg="4 records suppressed by sampling" suppressed=4`) {
+		t.Errorf("expected a summary line, got %q", receiver.String())
+	}
+
+	logger.LogDebug("struct", "function", "message", -1)
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(receiver.String(), "suppressed=1") {
+		t.Errorf("Close must log the pending summary, got %q", receiver.String())
+	}
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"context"
+	"io"
+)
+
+// RecordWriter can be implemented by a destination that needs the record in
+// addition to the formatted bytes, for instance to map the level onto its own
+// severities. It is used in place of Write when the destination implements it.
+type RecordWriter interface {
+	WriteRecord(r *Record, p []byte) (int, error)
+}
+
+// sink is a destination of a Logger with its own Formatter and minimum level.
+type sink struct {
+	writer       io.Writer
+	recordWriter RecordWriter
+	formatter    Formatter
+	level        LogLevel
+}
+
+func newSink(dst io.Writer, level LogLevel, f Formatter) *sink {
+	if f == nil {
+		f = new(TextFormatter)
+	}
+	var s *sink = &sink{writer: dst, formatter: f, level: level}
+	s.recordWriter, _ = dst.(RecordWriter)
+	return s
+}
+
+func (s *sink) write(r *Record) {
+	if s.recordWriter != nil {
+		s.recordWriter.WriteRecord(r, s.formatter.Format(r))
+	} else {
+		s.writer.Write(s.formatter.Format(r))
+	}
+}
+
+// setDefaultStructure is forwarded to the destinations using the default
+// structure of the Logger as an identifier, such as SyslogWriter.
+func (s *sink) setDefaultStructure(structure string) {
+	if w, ok := s.writer.(interface{ setDefaultStructure(string) }); ok {
+		w.setDefaultStructure(structure)
+	}
+}
+
+// flush flushes the destination, unless ctx is already done.
+func (s *sink) flush(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	switch w := s.writer.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Sync() error }:
+		// Sync fails on terminals and pipes, which do not need it anyway.
+		w.Sync()
+	}
+	return nil
+}
+
+// AddSink adds a destination receiving the records at or above level, that
+// is with a level lower or equal, rendered by f, a TextFormatter if f is nil.
+// The verbosity of the Logger still applies first: a sink with the level
+// LogLevelDebug only receives debug records when the verbosity allows them.
+// The sinks are shared with the derived loggers.
+func (l *Logger) AddSink(dst io.Writer, level LogLevel, f Formatter) {
+	l.out.addSink(newSink(dst, level, f))
+}
+
+// WithSink adds a destination to the Logger, see AddSink. The destination
+// given to NewLogger, when not nil, receives every record allowed by the
+// verbosity.
+func WithSink(dst io.Writer, level LogLevel, f Formatter) Option {
+	return func(l *Logger) {
+		l.AddSink(dst, level, f)
+	}
+}
+
+func (o *output) addSink(s *sink) {
+	o.mu.Lock()
+	if o.defaultStructure != "" {
+		s.setDefaultStructure(o.defaultStructure)
+	}
+	o.sinks = append(o.sinks, s)
+	o.mu.Unlock()
+}
+
+func (o *output) setDefaultStructure(structure string) {
+	o.mu.Lock()
+	o.defaultStructure = structure
+	for _, s := range o.sinks {
+		s.setDefaultStructure(structure)
+	}
+	o.mu.Unlock()
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSinks(t *testing.T) {
+	var stderr, file, structured *bytes.Buffer = bytes.NewBuffer([]byte{}), bytes.NewBuffer([]byte{}), bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelDebug, stderr, WithSink(file, LogLevelError, nil))
+	logger.With("k", "v").AddSink(structured, LogLevelWarning, new(LogfmtFormatter))
+
+	logger.LogDebug("struct", "function", "debug", -1)
+	logger.LogWarning("struct", "function", "warning", -1)
+	logger.LogError("struct", "function", "error", -1)
+	logger.LogTrace("struct", "function", "trace", -1)
+
+	if strings.Count(stderr.String(), "\n") != 3 {
+		t.Errorf("the primary destination must receive every record allowed by the verbosity, got %q", stderr.String())
+	}
+	if file.String() == "" || strings.Count(file.String(), "\n") != 1 || !strings.HasSuffix(file.String(), "[ERROR   ] struct -> function: error\n") {
+		t.Errorf("the error sink must only receive the error, got %q", file.String())
+	}
+	var lines []string = strings.Split(strings.TrimSuffix(structured.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "msg=warning") || !strings.HasSuffix(lines[1], "msg=error") {
+		t.Errorf("the logfmt sink must receive the warning and the error, got %q", structured.String())
+	}
+}
+
+func TestSinksFatal(t *testing.T) {
+	var file *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, nil, WithSink(file, LogLevelError, nil), WithExitFunc(func(int) {}))
+
+	logger.LogInfo("struct", "function", "info", -1)
+	logger.FatalInfo("struct", "function", "fatal", -1)
+	if file.String() == "" || !strings.HasSuffix(file.String(), "struct -> function: fatal\n") || strings.Contains(file.String(), "info\n") {
+		t.Errorf("the fatal records must reach every sink, got %q", file.String())
+	}
+}
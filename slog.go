@@ -0,0 +1,228 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	slogKeyStructure string = "structure"
+	slogKeyFunction  string = "function"
+	slogKeyId        string = "id"
+)
+
+const (
+	slogLevelTrace    slog.Level = slog.LevelDebug - 4
+	slogLevelNotice   slog.Level = slog.LevelInfo + 2
+	slogLevelCritical slog.Level = slog.LevelError + 2
+	slogLevelAlert    slog.Level = slog.LevelError + 4
+	slogLevelEmerge   slog.Level = slog.LevelError + 6
+)
+
+var slogLevelMap map[LogLevel]slog.Level = map[LogLevel]slog.Level{
+	LogLevelEmerge:   slogLevelEmerge,
+	LogLevelAlert:    slogLevelAlert,
+	LogLevelCritical: slogLevelCritical,
+	LogLevelError:    slog.LevelError,
+	LogLevelWarning:  slog.LevelWarn,
+	LogLevelNotice:   slogLevelNotice,
+	LogLevelInfo:     slog.LevelInfo,
+	LogLevelDebug:    slog.LevelDebug,
+	LogLevelTrace:    slogLevelTrace,
+}
+
+// SlogLevel returns the slog level matching level. The levels missing in slog
+// are placed between the slog ones: NOTICE is INFO+2, CRITICAL is ERROR+2,
+// ALERT is ERROR+4, EMERGE is ERROR+6 and TRACE is DEBUG-4.
+func SlogLevel(level LogLevel) slog.Level {
+	var l slog.Level
+	var exists bool
+	if l, exists = slogLevelMap[level]; !exists {
+		return slogLevelTrace
+	}
+	return l
+}
+
+// LogLevelFromSlog returns the LogLevel matching level, see SlogLevel. A slog
+// level between two of them gets the less severe one.
+func LogLevelFromSlog(level slog.Level) LogLevel {
+	switch {
+	case level >= slogLevelEmerge:
+		return LogLevelEmerge
+	case level >= slogLevelAlert:
+		return LogLevelAlert
+	case level >= slogLevelCritical:
+		return LogLevelCritical
+	case level >= slog.LevelError:
+		return LogLevelError
+	case level >= slog.LevelWarn:
+		return LogLevelWarning
+	case level >= slogLevelNotice:
+		return LogLevelNotice
+	case level >= slog.LevelInfo:
+		return LogLevelInfo
+	case level >= slog.LevelDebug:
+		return LogLevelDebug
+	default:
+		return LogLevelTrace
+	}
+}
+
+// SlogHandler is a slog.Handler writing through a Logger. The attributes
+// named structure, function and id fill the matching arguments of the Logger,
+// the default structure and function of the Logger are used otherwise. The
+// other attributes become fields, prefixed by their groups.
+type SlogHandler struct {
+	logger *Logger
+	group  string
+}
+
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return LogLevelFromSlog(level) <= h.logger.out.funcs.Load().maxLevel
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var r Record = Record{
+		Time:      record.Time,
+		Level:     LogLevelFromSlog(record.Level),
+		Structure: h.logger.defaultStructure,
+		Function:  h.logger.defaultFunction,
+		Id:        -1,
+		Message:   record.Message,
+		Context:   ctx,
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+
+	var fields []Field = make([]Field, 0, len(h.logger.fields)+record.NumAttrs())
+	fields = append(fields, h.logger.fields...)
+	record.Attrs(func(attr slog.Attr) bool {
+		if h.group == "" {
+			switch attr.Key {
+			case slogKeyStructure:
+				r.Structure = attr.Value.String()
+				return true
+			case slogKeyFunction:
+				r.Function = attr.Value.String()
+				return true
+			case slogKeyId:
+				if attr.Value.Kind() == slog.KindInt64 {
+					r.Id = int(attr.Value.Int64())
+					return true
+				}
+			}
+		}
+		fields = appendSlogAttr(fields, h.group, attr)
+		return true
+	})
+	if h.logger.out.caller != nil && record.PC != 0 {
+		r.Caller = callerFromPC(record.PC)
+		if h.logger.out.caller.Fill {
+			fillFromCaller(&r)
+		}
+	}
+	if !h.logger.out.funcs.Load().enabled(r.Level, r.Structure, r.Function) {
+		return nil
+	}
+	if h.logger.out.drop(r.Level, r.Structure, r.Function, r.Message) {
+		return nil
+	}
+	if h.logger.out.stack != nil && r.Level <= h.logger.out.stack.Level && record.PC != 0 {
+		r.Stack = stackFromPC(record.PC)
+	}
+	r.Fields = h.logger.out.contextFields(ctx, fields)
+	h.logger.out.write(&r)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []Field
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, h.group, attr)
+	}
+	return &SlogHandler{logger: h.logger.withFields(fields), group: h.group}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, group: h.group + name + "."}
+}
+
+func appendSlogAttr(fields []Field, prefix string, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	if attr.Value.Kind() != slog.KindGroup {
+		return append(fields, Field{Key: prefix + attr.Key, Value: attr.Value.Any()})
+	}
+	if attr.Key != "" {
+		prefix += attr.Key + "."
+	}
+	for _, member := range attr.Value.Group() {
+		fields = appendSlogAttr(fields, prefix, member)
+	}
+	return fields
+}
+
+// slogWriter is the destination of a Logger built by NewSlogLogger, it hands
+// the records over to a slog.Handler.
+type slogWriter struct {
+	handler slog.Handler
+}
+
+func (w *slogWriter) Write(p []byte) (int, error) {
+	var r Record = Record{Time: time.Now(), Level: LogLevelInfo, Id: -1, Message: string(p)}
+	return w.WriteRecord(&r, p)
+}
+
+func (w *slogWriter) WriteRecord(r *Record, p []byte) (int, error) {
+	var ctx context.Context = r.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var level slog.Level = SlogLevel(r.Level)
+	if !w.handler.Enabled(ctx, level) {
+		return len(p), nil
+	}
+
+	var record slog.Record = slog.NewRecord(r.Time, level, r.Message, r.Caller.PC)
+	record.AddAttrs(slog.String(slogKeyStructure, r.Structure), slog.String(slogKeyFunction, r.Function))
+	if r.Id >= 0 {
+		record.AddAttrs(slog.Int(slogKeyId, r.Id))
+	}
+	if len(r.Stack) > 0 {
+		record.AddAttrs(slog.Any("stack", r.Stack))
+	}
+	for _, field := range r.Fields {
+		record.AddAttrs(slog.Any(field.Key, field.Value))
+	}
+	if err := w.handler.Handle(ctx, record); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// noFormatter is used by the destinations ignoring the formatted bytes.
+type noFormatter struct{}
+
+func (f noFormatter) Format(r *Record) []byte {
+	return nil
+}
+
+// NewSlogLogger returns a Logger writing through h: each record is handed
+// over as a slog.Record with the structure, the function, the id when not
+// negative and the fields as attributes.
+func NewSlogLogger(level LogLevel, h slog.Handler, opts ...Option) *Logger {
+	var w *slogWriter = &slogWriter{handler: h}
+	opts = append([]Option{WithFormatter(noFormatter{})}, opts...)
+	return NewLogger(level, w, opts...)
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogLevels(t *testing.T) {
+	for lvl := LogLevelEmerge; lvl <= LogLevelTrace; lvl++ {
+		if got := LogLevelFromSlog(SlogLevel(lvl)); got != lvl {
+			t.Errorf("the level %s does not round trip, got %s", GetLevelName(lvl), GetLevelName(got))
+		}
+	}
+	if LogLevelFromSlog(slog.LevelWarn+1) != LogLevelWarning || LogLevelFromSlog(slog.LevelInfo-1) != LogLevelDebug {
+		t.Error("the slog levels between two levels must get the less severe one")
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, receiver, WithFormatter(new(LogfmtFormatter)))
+	logger.SetDefaultStructure("app")
+	logger.SetDefaultFunction("main")
+	var s *slog.Logger = slog.New(NewSlogHandler(logger.With("k", "v")))
+
+	s.With("a", 1).WithGroup("req").Info("hello 100%", "method", "GET", slog.Group("user", "id", 7))
+	var line string = receiver.String()
+	if !strings.Contains(line, " level=INFO structure=app function=main msg=\"hello 100%\" k=v a=1 req.method=GET req.user.id=7\n") {
+		t.Errorf("incorrect record, got %q", line)
+	}
+
+	receiver.Reset()
+	s.Warn("with args", "structure", "db", "function", "Query", "id", 12)
+	if !strings.Contains(receiver.String(), " level=WARNING structure=db function=Query id=12 msg=\"with args\" k=v\n") {
+		t.Errorf("the structure, function and id attributes must be used, got %q", receiver.String())
+	}
+
+	receiver.Reset()
+	s.Debug("disabled")
+	if receiver.String() != "" || s.Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("the verbosity of the Logger must apply, got %q", receiver.String())
+	}
+}
+
+func TestNewSlogLogger(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var handler slog.Handler = slog.NewJSONHandler(receiver, &slog.HandlerOptions{Level: slogLevelTrace})
+	var logger *Logger = NewSlogLogger(LogLevelDebug, handler)
+
+	logger.With("user_id", 42).LogCritical("struct", "function", "disk %s full", 3, "/var")
+	var entry map[string]any
+	if err := json.Unmarshal(receiver.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid json %q: %s", receiver.String(), err)
+	}
+	var expected map[string]any = map[string]any{
+		"level":     "ERROR+2",
+		"msg":       "disk /var full",
+		"structure": "struct",
+		"function":  "function",
+		"id":        float64(3),
+		"user_id":   float64(42),
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("incorrect value for the key %s, got %v, expecting %v", key, entry[key], value)
+		}
+	}
+
+	receiver.Reset()
+	logger.LogTrace("struct", "function", "disabled", -1)
+	if receiver.String() != "" {
+		t.Errorf("the verbosity of the Logger must apply, got %q", receiver.String())
+	}
+}
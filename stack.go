@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	stackMaxFrames   int = 64
+	stackMaxDumpSize int = 64 << 20
+)
+
+// StackConfig describes the stack traces attached to the records.
+type StackConfig struct {
+	// Level is the least severe level getting a stack trace, for instance
+	// LogLevelCritical for the EMERGE, ALERT and CRITICAL records. The records
+	// of the FatalXxx methods always get one, and FatalEmerge gets the dump of
+	// all the goroutines.
+	Level LogLevel
+}
+
+// WithStackTrace sets Record.Stack on the records at or above config.Level.
+// TextFormatter renders the stack as an indented block below the record, the
+// structured formatters as an array or a multi-line value.
+func WithStackTrace(config StackConfig) Option {
+	return func(l *Logger) {
+		l.out.stack = &config
+	}
+}
+
+// captureStack returns the frames of the current goroutine starting skip
+// frames above runtime.Callers.
+func captureStack(skip int) []string {
+	var pcs [stackMaxFrames]uintptr
+	var n int = runtime.Callers(skip, pcs[:])
+	return formatFrames(pcs[:n])
+}
+
+// stackFromPC returns the frames of the current goroutine starting at the
+// return program counter pc, such as slog.Record.PC. Only the frame of pc is
+// returned if it is not on the stack, for a record handled by another
+// goroutine.
+func stackFromPC(pc uintptr) []string {
+	var pcs [stackMaxFrames]uintptr
+	var n int = runtime.Callers(2, pcs[:])
+	for i := 0; i < n; i++ {
+		if pcs[i] == pc {
+			return formatFrames(pcs[i:n])
+		}
+	}
+	return formatFrames([]uintptr{pc})
+}
+
+// formatFrames returns the frames of the return program counters pcs, each as
+// "function (file:line)".
+func formatFrames(pcs []uintptr) []string {
+	if len(pcs) == 0 {
+		return nil
+	}
+	var stack []string = make([]string, 0, len(pcs))
+	var frames *runtime.Frames = runtime.CallersFrames(pcs)
+	for {
+		var frame runtime.Frame
+		var more bool
+		frame, more = frames.Next()
+		if frame.Function != "runtime.goexit" && frame.Function != "" {
+			stack = append(stack, frame.Function+" ("+frame.File+":"+strconv.Itoa(frame.Line)+")")
+		}
+		if !more {
+			return stack
+		}
+	}
+}
+
+// dumpGoroutines returns the lines of the stack traces of all the goroutines,
+// as printed by a panic, without the blank lines.
+func dumpGoroutines() []string {
+	var buf []byte = make([]byte, 64<<10)
+	for {
+		var n int = runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= stackMaxDumpSize {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	var lines []string
+	for _, line := range strings.Split(string(buf), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// appendStackText appends the stack as lines indented by a tab.
+func appendStackText(b []byte, stack []string) []byte {
+	for _, line := range stack {
+		b = append(b, "\n\t"...)
+		b = append(b, line...)
+	}
+	return b
+}
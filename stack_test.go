@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func stackTestHelper(l *Logger, level LogLevel) {
+	switch level {
+	case LogLevelCritical:
+		l.LogCritical("struct", "function", "critical", -1)
+	case LogLevelError:
+		l.LogError("struct", "function", "error", -1)
+	}
+}
+
+func TestStackTraceText(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithStackTrace(StackConfig{Level: LogLevelCritical}), WithExitFunc(func(int) {}))
+
+	stackTestHelper(logger, LogLevelError)
+	if strings.Count(buffer.String(), "\n") != 1 {
+		t.Errorf("the records below the level must not get a stack, got %q", buffer.String())
+	}
+
+	buffer.Reset()
+	stackTestHelper(logger, LogLevelCritical)
+	var lines []string = strings.Split(buffer.String(), "\n")
+	if len(lines) < 4 || !strings.HasSuffix(lines[0], "critical") ||
+		!strings.HasPrefix(lines[1], "\tgithub.com/mmaFR/logger.stackTestHelper (") ||
+		!strings.HasPrefix(lines[2], "\tgithub.com/mmaFR/logger.TestStackTraceText (") {
+		t.Errorf("the stack must start at the caller, got %q", buffer.String())
+	}
+
+	buffer.Reset()
+	logger.FatalDebug("struct", "function", "fatal", -1)
+	lines = strings.Split(buffer.String(), "\n")
+	if len(lines) < 3 || !strings.HasPrefix(lines[1], "\tgithub.com/mmaFR/logger.TestStackTraceText (") {
+		t.Errorf("the fatal records must get a stack, got %q", buffer.String())
+	}
+
+	buffer.Reset()
+	var done chan struct{} = make(chan struct{})
+	defer close(done)
+	go func() { <-done }()
+	logger.FatalEmerge("struct", "function", "fatal", -1)
+	if strings.Count(buffer.String(), "\n\tgoroutine ") < 2 || !strings.Contains(buffer.String(), "[running]:") {
+		t.Errorf("FatalEmerge must dump all the goroutines, got %q", buffer.String())
+	}
+}
+
+func TestStackTraceStructured(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithFormatter(new(JSONFormatter)), WithStackTrace(StackConfig{Level: LogLevelCritical}))
+
+	stackTestHelper(logger, LogLevelCritical)
+	var record struct {
+		Stack []string `json:"stack"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Stack) < 2 || !strings.HasPrefix(record.Stack[0], "github.com/mmaFR/logger.stackTestHelper (") || !strings.Contains(record.Stack[0], "stack_test.go:") {
+		t.Errorf("unexpected stack %q", record.Stack)
+	}
+
+	buffer.Reset()
+	logger.SetFormatter(new(LogfmtFormatter))
+	stackTestHelper(logger, LogLevelCritical)
+	if strings.Count(buffer.String(), "\n") != 1 || !strings.Contains(buffer.String(), ` stack="github.com/mmaFR/logger.stackTestHelper (`) || !strings.Contains(buffer.String(), `)\ngithub.com/mmaFR/logger.TestStackTraceStructured (`) {
+		t.Errorf("the logfmt stack must be a single quoted value, got %q", buffer.String())
+	}
+}
+
+func TestStackTraceSlog(t *testing.T) {
+	var buffer *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelTrace, buffer, WithStackTrace(StackConfig{Level: LogLevelCritical}))
+	var slogger *slog.Logger = slog.New(NewSlogHandler(logger))
+
+	slogger.Error("error")
+	if strings.Count(buffer.String(), "\n") != 1 {
+		t.Errorf("the records below the level must not get a stack, got %q", buffer.String())
+	}
+
+	buffer.Reset()
+	slogger.Log(context.Background(), SlogLevel(LogLevelCritical), "critical")
+	var lines []string = strings.Split(buffer.String(), "\n")
+	if len(lines) < 3 || !strings.HasSuffix(lines[0], "critical") || !strings.HasPrefix(lines[1], "\tgithub.com/mmaFR/logger.TestStackTraceSlog (") {
+		t.Errorf("the stack must start at the caller of the slog.Logger, got %q", buffer.String())
+	}
+}
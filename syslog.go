@@ -0,0 +1,290 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type SyslogFacility uint8
+
+const (
+	SyslogFacilityKern SyslogFacility = iota
+	SyslogFacilityUser
+	SyslogFacilityMail
+	SyslogFacilityDaemon
+	SyslogFacilityAuth
+	SyslogFacilitySyslog
+	SyslogFacilityLpr
+	SyslogFacilityNews
+	SyslogFacilityUucp
+	SyslogFacilityCron
+	SyslogFacilityAuthPriv
+	SyslogFacilityFtp
+	_
+	_
+	_
+	_
+	SyslogFacilityLocal0
+	SyslogFacilityLocal1
+	SyslogFacilityLocal2
+	SyslogFacilityLocal3
+	SyslogFacilityLocal4
+	SyslogFacilityLocal5
+	SyslogFacilityLocal6
+	SyslogFacilityLocal7
+)
+
+type SyslogFormat uint8
+
+const (
+	SyslogRFC5424 SyslogFormat = iota
+	SyslogRFC3164
+)
+
+const (
+	syslogRFC5424TimeLayout string = "2006-01-02T15:04:05.000000Z07:00"
+	syslogRFC3164TimeLayout string = "Jan _2 15:04:05"
+	syslogNilValue          string = "-"
+)
+
+var syslogLocalSockets []string = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+var syslogSeverityMap map[LogLevel]uint8 = map[LogLevel]uint8{
+	LogLevelEmerge:   0,
+	LogLevelAlert:    1,
+	LogLevelCritical: 2,
+	LogLevelError:    3,
+	LogLevelWarning:  4,
+	LogLevelNotice:   5,
+	LogLevelInfo:     6,
+	LogLevelDebug:    7,
+	LogLevelTrace:    7,
+}
+
+// SyslogConfig describes the syslog daemon to send the records to.
+type SyslogConfig struct {
+	// Network is "udp", "tcp", "unix" or "unixgram". When empty, the local
+	// daemon is reached through its usual Unix socket.
+	Network string
+	Address string
+	// Facility defaults to SyslogFacilityUser, as for syslog(3) the kernel
+	// facility cannot be used by a process.
+	Facility SyslogFacility
+	// AppName defaults to the default structure of the Logger, then to the
+	// name of the executable.
+	AppName  string
+	Hostname string
+	Format   SyslogFormat
+}
+
+// SyslogWriter sends each record as one syslog message, with the severity
+// derived from the level of the record. The formatted record, without its
+// trailing new line, is used as the message.
+type SyslogWriter struct {
+	mu               sync.Mutex
+	config           SyslogConfig
+	conn             net.Conn
+	stream           bool
+	defaultStructure string
+	pid              string
+}
+
+func NewSyslogWriter(config SyslogConfig) (*SyslogWriter, error) {
+	var w *SyslogWriter = &SyslogWriter{config: config, pid: strconv.Itoa(os.Getpid())}
+	if w.config.Facility == SyslogFacilityKern {
+		w.config.Facility = SyslogFacilityUser
+	}
+	if w.config.Hostname == "" {
+		w.config.Hostname, _ = os.Hostname()
+	}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// NewSyslogLogger returns a Logger writing to a new SyslogWriter, closed by
+// Logger.Close. The date and the level are left out of the messages as syslog
+// carries them on its own.
+func NewSyslogLogger(level LogLevel, config SyslogConfig, opts ...Option) (*Logger, error) {
+	var w *SyslogWriter
+	var err error
+	if w, err = NewSyslogWriter(config); err != nil {
+		return nil, err
+	}
+	opts = append([]Option{WithFormatter(&TextFormatter{DisableTime: true, DisableLevel: true})}, opts...)
+	var l *Logger = NewLogger(level, w, opts...)
+	l.out.closers = append(l.out.closers, w)
+	return l, nil
+}
+
+func (w *SyslogWriter) connect() error {
+	var err error
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	if w.config.Network != "" {
+		if w.conn, err = net.Dial(w.config.Network, w.config.Address); err != nil {
+			return err
+		}
+		w.stream = w.config.Network != "udp" && w.config.Network != "udp4" && w.config.Network != "udp6" && w.config.Network != "unixgram"
+		return nil
+	}
+	for _, path := range syslogLocalSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			if w.conn, err = net.Dial(network, path); err == nil {
+				w.stream = network == "unix"
+				return nil
+			}
+		}
+	}
+	return errors.New("logger: unable to connect to the local syslog daemon")
+}
+
+func (w *SyslogWriter) setDefaultStructure(s string) {
+	w.mu.Lock()
+	w.defaultStructure = s
+	w.mu.Unlock()
+}
+
+func (w *SyslogWriter) appName() string {
+	switch {
+	case w.config.AppName != "":
+		return w.config.AppName
+	case w.defaultStructure != "":
+		return w.defaultStructure
+	default:
+		return filepath.Base(os.Args[0])
+	}
+}
+
+// Write sends p as a message with the severity of LogLevelInfo.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	var r Record = Record{Time: time.Now(), Level: LogLevelInfo, Id: -1}
+	return w.WriteRecord(&r, p)
+}
+
+func (w *SyslogWriter) WriteRecord(r *Record, p []byte) (int, error) {
+	var msg []byte = w.message(r, p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var err error
+	if w.conn != nil {
+		if _, err = w.conn.Write(w.frame(msg)); err == nil {
+			return len(p), nil
+		}
+	}
+	// The daemon may have been restarted, one reconnection is attempted.
+	if err = w.connect(); err != nil {
+		return 0, err
+	}
+	if _, err = w.conn.Write(w.frame(msg)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *SyslogWriter) message(r *Record, p []byte) []byte {
+	var severity uint8
+	var exists bool
+	if severity, exists = syslogSeverityMap[r.Level]; !exists {
+		severity = syslogSeverityMap[LogLevelInfo]
+	}
+	for len(p) > 0 && p[len(p)-1] == '\n' {
+		p = p[:len(p)-1]
+	}
+
+	w.mu.Lock()
+	var appName string = w.appName()
+	w.mu.Unlock()
+
+	var b []byte = make([]byte, 0, 64+len(w.config.Hostname)+len(appName)+len(p))
+	b = append(b, '<')
+	b = strconv.AppendUint(b, uint64(w.config.Facility)*8+uint64(severity), 10)
+	b = append(b, '>')
+	if w.config.Format == SyslogRFC3164 {
+		b = r.Time.AppendFormat(b, syslogRFC3164TimeLayout)
+		b = append(b, ' ')
+		if w.config.Network != "" && w.config.Hostname != "" {
+			b = append(b, w.config.Hostname...)
+			b = append(b, ' ')
+		}
+		b = append(b, appName...)
+		b = append(b, '[')
+		b = append(b, w.pid...)
+		b = append(b, "]: "...)
+		return append(b, p...)
+	}
+
+	b = append(b, "1 "...)
+	b = r.Time.AppendFormat(b, syslogRFC5424TimeLayout)
+	b = append(b, ' ')
+	b = appendSyslogHeaderField(b, w.config.Hostname, 255)
+	b = append(b, ' ')
+	b = appendSyslogHeaderField(b, appName, 48)
+	b = append(b, ' ')
+	b = append(b, w.pid...)
+	b = append(b, ' ')
+	if r.Id >= 0 {
+		b = strconv.AppendInt(b, int64(r.Id), 10)
+	} else {
+		b = append(b, syslogNilValue...)
+	}
+	b = append(b, ' ')
+	b = append(b, syslogNilValue...)
+	b = append(b, ' ')
+	return append(b, p...)
+}
+
+// frame applies the octet counting of RFC 6587 to the RFC 5424 messages and
+// terminates the RFC 3164 messages by a new line on stream connections.
+func (w *SyslogWriter) frame(msg []byte) []byte {
+	if !w.stream {
+		return msg
+	}
+	if w.config.Format == SyslogRFC3164 {
+		return append(msg, '\n')
+	}
+	var b []byte = make([]byte, 0, len(msg)+8)
+	b = strconv.AppendInt(b, int64(len(msg)), 10)
+	b = append(b, ' ')
+	return append(b, msg...)
+}
+
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	var err error = w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// appendSyslogHeaderField keeps only the printable US-ASCII characters allowed
+// by RFC 5424 in the header fields, the nil value is used for an empty field.
+func appendSyslogHeaderField(b []byte, s string, max int) []byte {
+	var n int
+	for i := 0; i < len(s) && n < max; i++ {
+		if s[i] >= 33 && s[i] <= 126 {
+			b = append(b, s[i])
+			n++
+		}
+	}
+	if n == 0 {
+		return append(b, syslogNilValue...)
+	}
+	return b
+}
+
+var _ io.Writer = (*SyslogWriter)(nil)
+var _ RecordWriter = (*SyslogWriter)(nil)
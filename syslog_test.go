@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogUDP(t *testing.T) {
+	var conn net.PacketConn
+	var err error
+	if conn, err = net.ListenPacket("udp", "127.0.0.1:0"); err != nil {
+		t.Skipf("unable to listen on udp: %s", err)
+	}
+	defer conn.Close()
+
+	var logger *Logger
+	if logger, err = NewSyslogLogger(LogLevelDebug, SyslogConfig{Network: "udp", Address: conn.LocalAddr().String(), Facility: SyslogFacilityLocal0, Hostname: "host"}); err != nil {
+		t.Fatal(err)
+	}
+	logger.SetDefaultStructure("myapp")
+
+	type test struct {
+		log      logFunc
+		id       int
+		expected string
+	}
+	var tests []test = []test{
+		{log: logger.LogError, id: 12, expected: `^<131>1 \S+ host myapp \d+ 12 - struct -> function-12: my message$`},
+		{log: logger.LogEmerge, id: -1, expected: `^<128>1 \S+ host myapp \d+ - - struct -> function: my message$`},
+		{log: logger.LogDebug, id: -1, expected: `^<135>1 `},
+	}
+	var buffer []byte = make([]byte, 2048)
+	for _, test := range tests {
+		test.log("struct", "function", "my message", test.id)
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var n int
+		if n, _, err = conn.ReadFrom(buffer); err != nil {
+			t.Fatal(err)
+		}
+		if !regexp.MustCompile(test.expected).Match(buffer[:n]) {
+			t.Errorf("incorrect syslog message, got %q, expecting %s", buffer[:n], test.expected)
+		}
+	}
+
+	if err = logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if logger.out.primary.writer.(*SyslogWriter).conn != nil {
+		t.Error("Close must close the writer created by NewSyslogLogger")
+	}
+}
+
+func TestSyslogTCP(t *testing.T) {
+	var listener net.Listener
+	var err error
+	if listener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Skipf("unable to listen on tcp: %s", err)
+	}
+	defer listener.Close()
+
+	var writer *SyslogWriter
+	if writer, err = NewSyslogWriter(SyslogConfig{Network: "tcp", Address: listener.Addr().String(), AppName: "app", Hostname: "host", Format: SyslogRFC3164}); err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	var conn net.Conn
+	if conn, err = listener.Accept(); err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var logger *Logger = NewLogger(LogLevelInfo, writer, WithFormatter(&TextFormatter{DisableTime: true, DisableLevel: true}))
+	logger.LogWarning("struct", "function", "first", -1)
+	logger.LogInfo("struct", "function", "second", -1)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var reader *bufio.Reader = bufio.NewReader(conn)
+	for _, expected := range []string{
+		`^<12>\w{3} [ \d]\d \d\d:\d\d:\d\d host app\[\d+\]: struct -> function: first\n$`,
+		`^<14>\w{3} [ \d]\d \d\d:\d\d:\d\d host app\[\d+\]: struct -> function: second\n$`,
+	} {
+		var line string
+		if line, err = reader.ReadString('\n'); err != nil {
+			t.Fatal(err)
+		}
+		if !regexp.MustCompile(expected).MatchString(line) {
+			t.Errorf("incorrect syslog message, got %q, expecting %s", line, expected)
+		}
+	}
+}
+
+func TestSyslogTCPOctetCounting(t *testing.T) {
+	var listener net.Listener
+	var err error
+	if listener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Skipf("unable to listen on tcp: %s", err)
+	}
+	defer listener.Close()
+
+	var writer *SyslogWriter
+	if writer, err = NewSyslogWriter(SyslogConfig{Network: "tcp", Address: listener.Addr().String(), AppName: "app", Hostname: "host"}); err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	var conn net.Conn
+	if conn, err = listener.Accept(); err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	writer.Write([]byte("hello\n"))
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var reader *bufio.Reader = bufio.NewReader(conn)
+	var length string
+	if length, err = reader.ReadString(' '); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if n, err = strconv.Atoi(strings.TrimSuffix(length, " ")); err != nil {
+		t.Fatalf("invalid octet count %q", length)
+	}
+	var msg []byte = make([]byte, n)
+	if _, err = io.ReadFull(reader, msg); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(msg), "<14>1 ") || !strings.HasSuffix(string(msg), " app "+strconv.Itoa(os.Getpid())+" - - hello") {
+		t.Errorf("incorrect octet counted message, got %q", msg)
+	}
+}
+
+func TestSyslogUnixgram(t *testing.T) {
+	var path string = filepath.Join(t.TempDir(), "log.sock")
+	var conn net.PacketConn
+	var err error
+	if conn, err = net.ListenPacket("unixgram", path); err != nil {
+		t.Skipf("unable to listen on a unix socket: %s", err)
+	}
+	defer conn.Close()
+	defer os.Remove(path)
+
+	var writer *SyslogWriter
+	if writer, err = NewSyslogWriter(SyslogConfig{Network: "unixgram", Address: path, Facility: SyslogFacilityDaemon, AppName: "app"}); err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	var r Record = Record{Time: time.Now(), Level: LogLevelCritical, Id: -1}
+	writer.WriteRecord(&r, []byte("down\n"))
+
+	var buffer []byte = make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var n int
+	if n, _, err = conn.ReadFrom(buffer); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(buffer[:n]), "<26>1 ") || !strings.HasSuffix(string(buffer[:n]), " app "+strconv.Itoa(os.Getpid())+" - - down") {
+		t.Errorf("incorrect syslog message, got %q", buffer[:n])
+	}
+}
@@ -0,0 +1,194 @@
+package logger
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+)
+
+const (
+	traceFieldTraceId    string = "trace_id"
+	traceFieldSpanId     string = "span_id"
+	traceFieldTraceFlags string = "trace_flags"
+	traceparentVersion   string = "00"
+)
+
+var errInvalidTraceparent error = errors.New("logger: invalid traceparent")
+
+// SpanContext identifies a span as carried by the W3C traceparent header.
+type SpanContext struct {
+	TraceId    [16]byte
+	SpanId     [8]byte
+	TraceFlags byte
+}
+
+// IsValid reports whether both the trace id and the span id are not zero.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceId != [16]byte{} && sc.SpanId != [8]byte{}
+}
+
+// IsSampled reports whether the sampled flag is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.TraceFlags&0x01 != 0
+}
+
+// Traceparent renders sc as a version 00 traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	return traceparentVersion + "-" + hex.EncodeToString(sc.TraceId[:]) + "-" + hex.EncodeToString(sc.SpanId[:]) + "-" + hex.EncodeToString([]byte{sc.TraceFlags})
+}
+
+// ParseTraceparent parses a W3C traceparent header value such as
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func ParseTraceparent(s string) (SpanContext, error) {
+	var sc SpanContext
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' || (len(s) > 55 && s[55] != '-') {
+		return sc, errInvalidTraceparent
+	}
+	// Future versions may append fields, the version ff is forbidden.
+	if s[:2] == "ff" || (s[:2] == traceparentVersion && len(s) != 55) {
+		return sc, errInvalidTraceparent
+	}
+	var version [1]byte
+	var flags [1]byte
+	if _, err := hex.Decode(version[:], []byte(s[:2])); err != nil {
+		return sc, errInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.TraceId[:], []byte(s[3:35])); err != nil {
+		return sc, errInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.SpanId[:], []byte(s[36:52])); err != nil {
+		return sc, errInvalidTraceparent
+	}
+	if _, err := hex.Decode(flags[:], []byte(s[53:55])); err != nil {
+		return sc, errInvalidTraceparent
+	}
+	sc.TraceFlags = flags[0]
+	if !sc.IsValid() {
+		return sc, errInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// Span is the part of an active span used by a Logger: the records at or
+// above the level set by TraceConfig.EventLevel are added to it as events.
+type Span interface {
+	SpanContext() SpanContext
+	AddEvent(name string, fields []Field)
+}
+
+type spanContextKey struct{}
+type spanKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// ContextWithTraceparent returns a copy of ctx carrying the span described by
+// the traceparent header value.
+func ContextWithTraceparent(ctx context.Context, traceparent string) (context.Context, error) {
+	var sc SpanContext
+	var err error
+	if sc, err = ParseTraceparent(traceparent); err != nil {
+		return ctx, err
+	}
+	return ContextWithSpanContext(ctx, sc), nil
+}
+
+// ContextWithSpan returns a copy of ctx carrying span.
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span stored by ContextWithSpan.
+func SpanFromContext(ctx context.Context) (Span, bool) {
+	var span Span
+	var ok bool
+	span, ok = ctx.Value(spanKey{}).(Span)
+	return span, ok
+}
+
+// SpanContextFromContext returns the span context of the span stored by
+// ContextWithSpan, or else the one stored by ContextWithSpanContext.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if span, ok := SpanFromContext(ctx); ok {
+		return span.SpanContext(), true
+	}
+	var sc SpanContext
+	var ok bool
+	sc, ok = ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// TraceConfig describes the trace correlation of a Logger.
+type TraceConfig struct {
+	// SpanContext returns the span context active in ctx, it defaults to
+	// SpanContextFromContext. It allows to bridge a tracing library such as
+	// OpenTelemetry.
+	SpanContext func(ctx context.Context) (SpanContext, bool)
+	// Span returns the span active in ctx, it defaults to SpanFromContext.
+	Span func(ctx context.Context) (Span, bool)
+	// EventLevel enables the recording of the records at or above it as span
+	// events, LogLevelNull disables it.
+	EventLevel LogLevel
+}
+
+// WithTracing adds the trace_id, span_id and trace_flags fields to the records
+// logged with a context carrying a valid span, and records the most severe
+// ones as span events.
+func WithTracing(config TraceConfig) Option {
+	if config.SpanContext == nil {
+		config.SpanContext = SpanContextFromContext
+	}
+	if config.Span == nil {
+		config.Span = SpanFromContext
+	}
+	return func(l *Logger) {
+		l.AddContextExtractor(traceExtractor(config.SpanContext))
+		l.out.trace = &config
+	}
+}
+
+func traceExtractor(spanContext func(ctx context.Context) (SpanContext, bool)) ContextExtractor {
+	return func(ctx context.Context) []Field {
+		var sc SpanContext
+		var ok bool
+		if sc, ok = spanContext(ctx); !ok || !sc.IsValid() {
+			return nil
+		}
+		return []Field{
+			{Key: traceFieldTraceId, Value: hex.EncodeToString(sc.TraceId[:])},
+			{Key: traceFieldSpanId, Value: hex.EncodeToString(sc.SpanId[:])},
+			{Key: traceFieldTraceFlags, Value: hex.EncodeToString([]byte{sc.TraceFlags})},
+		}
+	}
+}
+
+// recordSpanEvent adds r as an event of the span active in its context, named
+// after the message, with the level, structure, function, id and fields as
+// attributes.
+func (o *output) recordSpanEvent(r *Record) {
+	if o.trace == nil || r.Context == nil || r.Level > o.trace.EventLevel {
+		return
+	}
+	var span Span
+	var ok bool
+	if span, ok = o.trace.Span(r.Context); !ok {
+		return
+	}
+	var fields []Field = make([]Field, 0, 4+len(r.Fields))
+	fields = append(fields,
+		Field{Key: "level", Value: GetLevelName(r.Level)},
+		Field{Key: "structure", Value: r.Structure},
+		Field{Key: "function", Value: r.Function},
+	)
+	if r.Id >= 0 {
+		fields = append(fields, Field{Key: "id", Value: r.Id})
+	}
+	for _, field := range r.Fields {
+		if field.Key != traceFieldTraceId && field.Key != traceFieldSpanId && field.Key != traceFieldTraceFlags {
+			fields = append(fields, field)
+		}
+	}
+	span.AddEvent(r.Message, fields)
+}
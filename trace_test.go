@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type memorySpan struct {
+	mu     sync.Mutex
+	sc     SpanContext
+	events []string
+}
+
+func (s *memorySpan) SpanContext() SpanContext {
+	return s.sc
+}
+
+func (s *memorySpan) AddEvent(name string, fields []Field) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var b []byte = appendFieldsText([]byte(name), fields)
+	s.events = append(s.events, string(b))
+}
+
+const testTraceparent string = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	var sc SpanContext
+	var err error
+	if sc, err = ParseTraceparent(testTraceparent); err != nil {
+		t.Fatal(err)
+	}
+	if !sc.IsValid() || !sc.IsSampled() || sc.Traceparent() != testTraceparent {
+		t.Errorf("incorrect span context %+v", sc)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01",
+	} {
+		if _, err = ParseTraceparent(invalid); err == nil {
+			t.Errorf("the traceparent %q must be rejected", invalid)
+		}
+	}
+	if _, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"); err != nil {
+		t.Errorf("the fields appended by a future version must be accepted: %s", err)
+	}
+}
+
+func TestTraceCorrelation(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, receiver, WithTracing(TraceConfig{EventLevel: LogLevelError}))
+
+	var ctx context.Context
+	var err error
+	if ctx, err = ContextWithTraceparent(context.Background(), testTraceparent); err != nil {
+		t.Fatal(err)
+	}
+	logger.LogInfoCtx(ctx, "struct", "function", "my message", -1)
+	if !strings.HasSuffix(receiver.String(), "my message trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 trace_flags=01\n") {
+		t.Errorf("the trace fields are missing, got %q", receiver.String())
+	}
+
+	receiver.Reset()
+	logger.LogInfoCtx(context.Background(), "struct", "function", "my message", -1)
+	if !strings.HasSuffix(receiver.String(), "my message\n") {
+		t.Errorf("no trace field expected without span, got %q", receiver.String())
+	}
+}
+
+func TestTraceSpanEvents(t *testing.T) {
+	var receiver *bytes.Buffer = bytes.NewBuffer([]byte{})
+	var logger *Logger = NewLogger(LogLevelInfo, receiver, WithTracing(TraceConfig{EventLevel: LogLevelError}))
+	var span *memorySpan = &memorySpan{sc: SpanContext{TraceId: [16]byte{1}, SpanId: [8]byte{2}, TraceFlags: 1}}
+	var ctx context.Context = ContextWithSpan(context.Background(), span)
+
+	logger.With("k", "v").WithContext(ctx).LogCritical("struct", "function", "disk full", 3)
+	logger.LogErrorCtx(ctx, "struct", "function", "retrying", -1)
+	logger.LogWarningCtx(ctx, "struct", "function", "slow", -1)
+
+	var expected []string = []string{
+		"disk full level=CRITICAL structure=struct function=function id=3 k=v",
+		"retrying level=ERROR structure=struct function=function",
+	}
+	if strings.Join(span.events, "|") != strings.Join(expected, "|") {
+		t.Errorf("incorrect span events, got %q, expecting %q", span.events, expected)
+	}
+	if !strings.Contains(receiver.String(), "trace_id=01000000000000000000000000000000 span_id=0200000000000000 trace_flags=01\n") {
+		t.Errorf("the trace fields must come from the span, got %q", receiver.String())
+	}
+}